@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/deadsy/pdev/devices/rei2c"
+	"periph.io/x/periph/conn/gpio/gpioreg"
 	"periph.io/x/periph/conn/i2c"
 	"periph.io/x/periph/conn/i2c/i2creg"
 	"periph.io/x/periph/conn/physic"
@@ -38,8 +39,9 @@ func printPin(fn string, p pin.Pin) {
 func mainImpl() error {
 
 	busId := flag.String("bus", "", "I²C bus")
-	devAddr := flag.Uint("adr", rei2c.DefaultAddress, "I²C device address")
+	devAddr := flag.Uint("adr", 0, "I²C device address (0 is the board default)")
 	busSpeed := flag.Int("hz", 0, "I²C bus speed")
+	intPin := flag.String("int", "", "device interrupt pin")
 
 	flag.Parse()
 
@@ -73,30 +75,67 @@ func mainImpl() error {
 		}
 	}
 
-	// TODO device interrupt pin
+	if *intPin != "" {
+		p := gpioreg.ByName(*intPin)
+		if p == nil {
+			return fmt.Errorf("couldn't open interrupt pin %s", *intPin)
+		}
+		printPin("INT", p)
+		opts.IntPin = p
+	}
 
-	opts.RGB = true
-	opts.DoublePush = 50
+	opts.DoublePush = 500 * time.Millisecond
+	opts.Gestures = &rei2c.Gestures{
+		LongPress:  time.Second,
+		MultiClick: 400 * time.Millisecond,
+		PressStep:  10,
+	}
 
 	dev, err := rei2c.New(i2cBus, &opts)
 	if err != nil {
 		return fmt.Errorf("couldn't open rei2c: %s", err)
 	}
 
-	fmt.Printf("%s\n", dev)
+	info := dev.Info()
+	fmt.Printf("%s %s\n", dev, info)
 
-	dev.WrLED(rei2c.RGB{0, 0, 255})
-	x, err := dev.RdLED()
-	fmt.Printf("%d %d %d %v\n", x.R, x.G, x.B, err)
+	// the Mini has no RGB LED
+	if info.RGB {
+		g := dev.GeneralConfig()
+		g.RGB = true
+		if err := dev.SetGeneralConfig(g); err != nil {
+			return err
+		}
+		if err := dev.WrLED(rei2c.RGB{R: 0, G: 0, B: 255}); err != nil {
+			return err
+		}
+		x, err := dev.RdLED()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("led %d %d %d\n", x.R, x.G, x.B)
+	}
 
-	dev.WrCntMin(0)
-	dev.WrCntMax(255)
-	dev.WrCntVal(0)
-	dev.WrCntStep(1)
+	if err := dev.SetCounterMin(-100); err != nil {
+		return err
+	}
+	if err := dev.SetCounterMax(1000); err != nil {
+		return err
+	}
+	if err := dev.SetCounter(0); err != nil {
+		return err
+	}
+	if err := dev.SetCounterStep(1); err != nil {
+		return err
+	}
+
+	// flash the LED on a button press
+	if info.RGB {
+		dev.AnimateOn(rei2c.EventPress, &rei2c.Pulse{Flash: rei2c.RGB{R: 255}, Duration: 300 * time.Millisecond}, 0)
+	}
 
-	for {
-		dev.Poll()
-		time.Sleep(50 * time.Millisecond)
+	for e := range dev.Events() {
+		fmt.Printf("%s\n", e)
 	}
 
 	return dev.Halt()
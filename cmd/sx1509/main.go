@@ -11,7 +11,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"time"
 
 	"github.com/deadsy/pdev/devices/sx1509"
 	"periph.io/x/periph/conn/i2c"
@@ -76,11 +75,11 @@ func mainImpl() error {
 	// TODO device interrupt pin
 
 	opts.Init = []sx1509.RegInit{
-		{sx1509.RegClock, 0x50},
-		{sx1509.RegMisc, 0x10},
-		{sx1509.RegDirA, 0x00},
-		{sx1509.RegOpenDrainA, 0xff},
-		{sx1509.RegPullUpB, 0xff},
+		{Reg: sx1509.RegClock, Val: 0x50},
+		{Reg: sx1509.RegMisc, Val: 0x10},
+		{Reg: sx1509.RegDirA, Val: 0x00},
+		{Reg: sx1509.RegOpenDrainA, Val: 0xff},
+		{Reg: sx1509.RegPullUpB, Val: 0xff},
 	}
 
 	dev, err := sx1509.New(i2cBus, &opts)
@@ -90,9 +89,8 @@ func mainImpl() error {
 
 	fmt.Printf("%s\n", dev)
 
-	for {
-		dev.Poll()
-		time.Sleep(4 * time.Millisecond)
+	for e := range dev.Events() {
+		fmt.Printf("%s\n", e)
 	}
 
 	return dev.Halt()
@@ -0,0 +1,115 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"math"
+	"time"
+)
+
+//-----------------------------------------------------------------------------
+// counter acceleration
+// The time between rotation events is used to scale the counter step. The
+// accelerated value is written back to CVAL, clamped (or wrapped) to the
+// CMIN..CMAX range, so the device counter always matches the value reported
+// to the application.
+
+// Accel is a counter acceleration profile.
+type Accel struct {
+	// Slow is the time between rotation events at or above which the step is not scaled.
+	Slow time.Duration
+	// Fast is the time between rotation events at or below which the step is scaled by Max.
+	Fast time.Duration
+	// Max is the maximum step multiplier.
+	Max float64
+	// Curve is the exponent of the acceleration curve between Slow and Fast. 0 is linear.
+	Curve float64
+}
+
+// factor returns the step multiplier for a time between rotation events.
+func (a *Accel) factor(dt time.Duration) float64 {
+	if a.Max <= 1 || dt >= a.Slow {
+		return 1
+	}
+	if dt <= a.Fast || a.Slow <= a.Fast {
+		return a.Max
+	}
+	x := float64(a.Slow-dt) / float64(a.Slow-a.Fast)
+	if a.Curve > 0 {
+		x = math.Pow(x, a.Curve)
+	}
+	return 1 + (a.Max-1)*x
+}
+
+// accelerate applies the acceleration profile to a new counter value.
+// It returns the accelerated counter value and the status with RMAX/RMIN set
+// if the accelerated value was clamped.
+func (d *Dev) accelerate(status uint8, n int32, now time.Time) (int32, uint8, error) {
+	prev, last, valid := d.count, d.countTime, d.countValid
+	d.count, d.countTime, d.countValid = n, now, true
+	if !valid {
+		return n, status, nil
+	}
+	var dir int64
+	switch status & (statusRINC | statusRDEC) {
+	case statusRINC:
+		dir = 1
+	case statusRDEC:
+		dir = -1
+	default:
+		return n, status, nil
+	}
+	f := d.opts.Accel.factor(now.Sub(last))
+	if f <= 1 {
+		return n, status, nil
+	}
+	delta := int64(n) - int64(prev)
+	if delta*dir <= 0 {
+		// the counter wrapped or was clamped, use a single step
+		step, err := d.rdCounterReg(d.v.istep)
+		if err != nil {
+			return n, status, err
+		}
+		delta = dir * int64(step)
+	}
+	lo, err := d.rdCounterReg(d.v.cmin)
+	if err != nil {
+		return n, status, err
+	}
+	hi, err := d.rdCounterReg(d.v.cmax)
+	if err != nil {
+		return n, status, err
+	}
+	min, max := int64(lo), int64(hi)
+	span := max - min + 1
+	if span <= 0 {
+		// no valid counter range to accelerate within
+		return n, status, nil
+	}
+	x := int64(prev) + int64(math.Round(float64(delta)*f))
+	switch {
+	case x > max && d.gconf&gconfWRAPE != 0:
+		x = min + (x-max-1)%span
+	case x > max:
+		x = max
+		status |= statusRMAX
+	case x < min && d.gconf&gconfWRAPE != 0:
+		x = max - (min-x-1)%span
+	case x < min:
+		x = min
+		status |= statusRMIN
+	}
+	if int32(x) != n {
+		err := d.wrCounterReg(d.v.cval, int32(x))
+		if err != nil {
+			return n, status, err
+		}
+	}
+	d.count = int32(x)
+	return int32(x), status, nil
+}
+
+//-----------------------------------------------------------------------------
@@ -0,0 +1,299 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+//-----------------------------------------------------------------------------
+// LED animation patterns
+
+// Pattern is an LED animation pattern.
+type Pattern interface {
+	// Color returns the LED color at time t after the pattern started.
+	// It returns false when the pattern has finished.
+	Color(t time.Duration) (RGB, bool)
+}
+
+// cycles returns the position (0..1) within the current cycle of a periodic
+// pattern, and false after count cycles (count == 0 repeats forever).
+func cycles(t, period time.Duration, count int) (float64, bool) {
+	if period <= 0 {
+		return 0, false
+	}
+	n := int(t / period)
+	if count > 0 && n >= count {
+		return 0, false
+	}
+	return float64(t%period) / float64(period), true
+}
+
+// Blink alternates between two colors.
+type Blink struct {
+	On, Off RGB           // on and off colors
+	Period  time.Duration // blink period (on time + off time)
+	Count   int           // number of blinks, 0 is forever
+}
+
+// Color implements Pattern.
+func (p *Blink) Color(t time.Duration) (RGB, bool) {
+	x, ok := cycles(t, p.Period, p.Count)
+	if x < 0.5 {
+		return p.On, ok
+	}
+	return p.Off, ok
+}
+
+// Breathe smoothly ramps the brightness of a color up and down.
+type Breathe struct {
+	Peak   RGB           // color at full brightness
+	Period time.Duration // breathing period
+	Count  int           // number of breaths, 0 is forever
+}
+
+// Color implements Pattern.
+func (p *Breathe) Color(t time.Duration) (RGB, bool) {
+	x, ok := cycles(t, p.Period, p.Count)
+	return p.Peak.Brightness((1 - math.Cos(2*math.Pi*x)) / 2), ok
+}
+
+// Pulse flashes a color and fades it out.
+type Pulse struct {
+	Flash    RGB           // flash color
+	Duration time.Duration // fade out time
+}
+
+// Color implements Pattern.
+func (p *Pulse) Color(t time.Duration) (RGB, bool) {
+	if t >= p.Duration {
+		return RGB{}, false
+	}
+	return p.Flash.Brightness(1 - float64(t)/float64(p.Duration)), true
+}
+
+// Rainbow cycles through the hues.
+type Rainbow struct {
+	Period     time.Duration // hue cycle period
+	Brightness float64       // brightness (0..1), 0 is full brightness
+}
+
+// Color implements Pattern.
+func (p *Rainbow) Color(t time.Duration) (RGB, bool) {
+	x, ok := cycles(t, p.Period, 0)
+	v := p.Brightness
+	if v == 0 {
+		v = 1
+	}
+	return HSV{H: 360 * x, S: 1, V: v}.RGB(), ok
+}
+
+//-----------------------------------------------------------------------------
+// animation scheduler
+// The highest priority running pattern drives the LED. For equal priorities
+// the most recently started pattern wins. When no pattern is running the LED
+// shows the base color set with WrLED.
+
+// animationPeriod is the LED update period for animations.
+const animationPeriod = 20 * time.Millisecond
+
+// animation is a running pattern.
+type animation struct {
+	id    int
+	p     Pattern
+	prio  int
+	start time.Time
+}
+
+// trigger is a pattern started by an event.
+type trigger struct {
+	p    Pattern
+	prio int
+	id   int // id of the running animation
+}
+
+// animator schedules the LED animations.
+type animator struct {
+	mu     sync.Mutex // taken after Dev.mu when both are held
+	anims  []*animation
+	on     map[EventType]*trigger
+	nextID int
+	wake   chan struct{}
+	base   RGB  // LED color when no pattern is running
+	shown  bool // an animation color is on the LED
+	last   RGB  // last animation color written
+	failed bool // the last LED write failed
+}
+
+// Animate starts an LED animation pattern with a priority.
+// It returns an id for StopAnimation.
+func (d *Dev) Animate(p Pattern, prio int) int {
+	a := &d.anim
+	a.mu.Lock()
+	id := a.start(p, prio)
+	a.mu.Unlock()
+	a.poke()
+	return id
+}
+
+// StopAnimation stops an LED animation pattern.
+func (d *Dev) StopAnimation(id int) {
+	a := &d.anim
+	a.mu.Lock()
+	a.stop(id)
+	a.mu.Unlock()
+	a.poke()
+}
+
+// AnimateOn starts the LED animation pattern each time an event of type t occurs.
+// A running pattern started by the same event type is restarted. A nil pattern
+// removes the trigger.
+func (d *Dev) AnimateOn(t EventType, p Pattern, prio int) {
+	a := &d.anim
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if old, ok := a.on[t]; ok {
+		a.stop(old.id)
+		delete(a.on, t)
+	}
+	if p != nil {
+		if a.on == nil {
+			a.on = make(map[EventType]*trigger)
+		}
+		a.on[t] = &trigger{p: p, prio: prio}
+	}
+}
+
+// start adds a running pattern. a.mu must be held.
+func (a *animator) start(p Pattern, prio int) int {
+	a.nextID++
+	a.anims = append(a.anims, &animation{
+		id:    a.nextID,
+		p:     p,
+		prio:  prio,
+		start: time.Now(),
+	})
+	return a.nextID
+}
+
+// stop removes a running pattern. a.mu must be held.
+func (a *animator) stop(id int) {
+	for i, x := range a.anims {
+		if x.id == id {
+			a.anims = append(a.anims[:i], a.anims[i+1:]...)
+			return
+		}
+	}
+}
+
+// trigger starts the pattern for an event type.
+func (a *animator) trigger(t EventType) {
+	a.mu.Lock()
+	tr, ok := a.on[t]
+	if ok {
+		a.stop(tr.id)
+		tr.id = a.start(tr.p, tr.prio)
+	}
+	a.mu.Unlock()
+	if ok {
+		a.poke()
+	}
+}
+
+// poke wakes the animator goroutine.
+func (a *animator) poke() {
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+// setBase records the base LED color.
+// It returns true if an animation is on the LED.
+func (a *animator) setBase(rgb RGB) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.base = rgb
+	return a.shown
+}
+
+// runAnimator is the LED animation goroutine.
+// It only ticks while a pattern is running, otherwise it waits to be woken.
+func (d *Dev) runAnimator() {
+	defer d.wg.Done()
+	for {
+		var tick <-chan time.Time
+		if d.anim.running() {
+			tick = time.After(animationPeriod)
+		}
+		select {
+		case <-d.done:
+			return
+		case <-d.anim.wake:
+		case <-tick:
+		}
+		d.animate(time.Now())
+	}
+}
+
+// running returns true if a pattern is running.
+func (a *animator) running() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.anims) != 0
+}
+
+// animate updates the LED with the current animation color.
+// The device lock is held across the color choice and the LED write so a
+// concurrent WrLED can't be overwritten with a stale base color.
+func (d *Dev) animate(now time.Time) {
+	a := &d.anim
+	d.mu.Lock()
+	a.mu.Lock()
+	// find the highest priority running pattern
+	var top *animation
+	var color RGB
+	running := a.anims[:0]
+	for _, x := range a.anims {
+		c, ok := x.p.Color(now.Sub(x.start))
+		if !ok {
+			continue
+		}
+		running = append(running, x)
+		if top == nil || x.prio >= top.prio {
+			top = x
+			color = c
+		}
+	}
+	a.anims = running
+	// work out what to write
+	write := false
+	if top != nil {
+		write = !a.shown || color != a.last
+		a.shown = true
+		a.last = color
+	} else if a.shown {
+		// restore the base color
+		write = true
+		color = a.base
+		a.shown = false
+	}
+	a.mu.Unlock()
+	if !write {
+		d.mu.Unlock()
+		return
+	}
+	err := d.wrLED(color)
+	d.mu.Unlock()
+	if err != nil && !a.failed {
+		d.sendError(err)
+	}
+	a.failed = err != nil
+}
+
+//-----------------------------------------------------------------------------
@@ -0,0 +1,202 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"math"
+)
+
+//-----------------------------------------------------------------------------
+// color spaces
+
+// HSV is a color in the hue, saturation, value color space.
+type HSV struct {
+	H float64 // hue in degrees (0..360)
+	S float64 // saturation (0..1)
+	V float64 // value (0..1)
+}
+
+// RGB converts the color to RGB.
+func (c HSV) RGB() RGB {
+	h := math.Mod(c.H, 360)
+	if h < 0 {
+		h += 360
+	}
+	s := clamp01(c.S)
+	v := clamp01(c.V)
+	chroma := v * s
+	return hueRGB(h, chroma, v-chroma)
+}
+
+// HSL is a color in the hue, saturation, lightness color space.
+type HSL struct {
+	H float64 // hue in degrees (0..360)
+	S float64 // saturation (0..1)
+	L float64 // lightness (0..1)
+}
+
+// RGB converts the color to RGB.
+func (c HSL) RGB() RGB {
+	h := math.Mod(c.H, 360)
+	if h < 0 {
+		h += 360
+	}
+	s := clamp01(c.S)
+	l := clamp01(c.L)
+	chroma := (1 - math.Abs(2*l-1)) * s
+	return hueRGB(h, chroma, l-chroma/2)
+}
+
+// hueRGB returns the RGB color for a hue, chroma and lightness offset.
+func hueRGB(h, chroma, m float64) RGB {
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = chroma, x, 0
+	case h < 120:
+		r, g, b = x, chroma, 0
+	case h < 180:
+		r, g, b = 0, chroma, x
+	case h < 240:
+		r, g, b = 0, x, chroma
+	case h < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	return RGB{R: unit8(r + m), G: unit8(g + m), B: unit8(b + m)}
+}
+
+// Kelvin returns the RGB color of a black body at a color temperature (1000..40000K).
+func Kelvin(k float64) RGB {
+	t := math.Min(math.Max(k, 1000), 40000) / 100
+	var r, g, b float64
+	if t <= 66 {
+		r = 255
+		g = 99.4708025861*math.Log(t) - 161.1195681661
+	} else {
+		r = 329.698727446 * math.Pow(t-60, -0.1332047592)
+		g = 288.1221695283 * math.Pow(t-60, -0.0755148492)
+	}
+	switch {
+	case t >= 66:
+		b = 255
+	case t <= 19:
+		b = 0
+	default:
+		b = 138.5177312231*math.Log(t-10) - 305.0447927307
+	}
+	return RGB{R: unit8(r / 255), G: unit8(g / 255), B: unit8(b / 255)}
+}
+
+//-----------------------------------------------------------------------------
+// brightness
+
+// gamma is the exponent used to convert perceived brightness to LED intensity.
+const gamma = 2.2
+
+// Gamma returns the gamma corrected color.
+// The LED intensity of each channel is adjusted so that equal steps in the
+// color value appear as equal steps in brightness.
+func (c RGB) Gamma() RGB {
+	return RGB{R: gamma8(c.R), G: gamma8(c.G), B: gamma8(c.B)}
+}
+
+// Brightness returns the color scaled by a perceived brightness (0..1).
+func (c RGB) Brightness(b float64) RGB {
+	k := math.Pow(clamp01(b), gamma)
+	return RGB{
+		R: unit8(float64(c.R) * k / 255),
+		G: unit8(float64(c.G) * k / 255),
+		B: unit8(float64(c.B) * k / 255),
+	}
+}
+
+// Lerp returns the linear interpolation between colors a and b (t = 0..1).
+func Lerp(a, b RGB, t float64) RGB {
+	t = clamp01(t)
+	lerp := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return RGB{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B)}
+}
+
+// Gradient is a color gradient with evenly spaced color stops.
+type Gradient []RGB
+
+// At returns the gradient color at position t (0..1).
+func (g Gradient) At(t float64) RGB {
+	switch len(g) {
+	case 0:
+		return RGB{}
+	case 1:
+		return g[0]
+	}
+	x := clamp01(t) * float64(len(g)-1)
+	i := int(x)
+	if i >= len(g)-1 {
+		return g[len(g)-1]
+	}
+	return Lerp(g[i], g[i+1], x-float64(i))
+}
+
+//-----------------------------------------------------------------------------
+
+// clamp01 limits x to 0..1.
+func clamp01(x float64) float64 {
+	return math.Min(math.Max(x, 0), 1)
+}
+
+// unit8 converts 0..1 to 0..255.
+func unit8(x float64) uint8 {
+	return uint8(math.Round(clamp01(x) * 255))
+}
+
+// gamma8 gamma corrects an 8-bit intensity.
+func gamma8(x uint8) uint8 {
+	return unit8(math.Pow(float64(x)/255, gamma))
+}
+
+//-----------------------------------------------------------------------------
+// counter color
+
+// showCounter sets the LED to the gradient color for the counter value
+// position within the counter minimum and maximum.
+func (d *Dev) showCounter(val float64) error {
+	if len(d.opts.Gradient) == 0 {
+		return nil
+	}
+	var min, max float64
+	if d.isFloat() {
+		lo, err := d.rdFloatReg(d.v.cmin)
+		if err != nil {
+			return err
+		}
+		hi, err := d.rdFloatReg(d.v.cmax)
+		if err != nil {
+			return err
+		}
+		min, max = float64(lo), float64(hi)
+	} else {
+		lo, err := d.rdCounterReg(d.v.cmin)
+		if err != nil {
+			return err
+		}
+		hi, err := d.rdCounterReg(d.v.cmax)
+		if err != nil {
+			return err
+		}
+		min, max = float64(lo), float64(hi)
+	}
+	var t float64
+	if max > min {
+		t = (val - min) / (max - min)
+	}
+	return d.setLED(d.opts.Gradient.At(t))
+}
+
+//-----------------------------------------------------------------------------
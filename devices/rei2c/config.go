@@ -0,0 +1,232 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+//-----------------------------------------------------------------------------
+// configuration snapshot
+// A Config holds the raw values of the registers from GCONF to FADEGP.
+// The status registers are not part of the configuration. ESTATUS and I2STATUS
+// are cleared when read, and FSTATUS is a read-only live status of the fades.
+// The EEPROM bank select and reset bits of GCONF are not included.
+
+// Config is a snapshot of the encoder configuration registers.
+type Config struct {
+	GCONF    uint8  `json:"gconf"`
+	GP1CONF  uint8  `json:"gp1conf"`
+	GP2CONF  uint8  `json:"gp2conf"`
+	GP3CONF  uint8  `json:"gp3conf"`
+	INTCONF  uint8  `json:"intconf"`
+	CVAL     uint32 `json:"cval"`
+	CMAX     uint32 `json:"cmax"`
+	CMIN     uint32 `json:"cmin"`
+	ISTEP    uint32 `json:"istep"`
+	RLED     uint8  `json:"rled"`
+	GLED     uint8  `json:"gled"`
+	BLED     uint8  `json:"bled"`
+	GP1REG   uint8  `json:"gp1reg"`
+	GP2REG   uint8  `json:"gp2reg"`
+	GP3REG   uint8  `json:"gp3reg"`
+	ANTBOUNC uint8  `json:"antbounc"`
+	DPPERIOD uint8  `json:"dpperiod"`
+	FADERGB  uint8  `json:"fadergb"`
+	FADEGP   uint8  `json:"fadegp"`
+}
+
+// configReg describes a configuration register.
+type configReg struct {
+	name string
+	addr uint8
+	u8   func(c *Config) *uint8  // 1 byte register
+	u32  func(c *Config) *uint32 // 4 byte counter register
+	gp   int                     // GP pin number for a GPxREG register
+	unit time.Duration           // time unit for a timer register
+	hex  bool                    // show the value in hex
+}
+
+// configRegs are the configuration registers in the order they are written.
+// The counter value is written after the counter limits.
+var configRegs = []configReg{
+	{name: "GCONF", addr: RegGCONF, u8: func(c *Config) *uint8 { return &c.GCONF }, hex: true},
+	{name: "GP1CONF", addr: RegGP1CONF, u8: func(c *Config) *uint8 { return &c.GP1CONF }, hex: true},
+	{name: "GP2CONF", addr: RegGP2CONF, u8: func(c *Config) *uint8 { return &c.GP2CONF }, hex: true},
+	{name: "GP3CONF", addr: RegGP3CONF, u8: func(c *Config) *uint8 { return &c.GP3CONF }, hex: true},
+	{name: "INTCONF", addr: RegINTCONF, u8: func(c *Config) *uint8 { return &c.INTCONF }, hex: true},
+	{name: "CMAX", addr: RegCMAX, u32: func(c *Config) *uint32 { return &c.CMAX }},
+	{name: "CMIN", addr: RegCMIN, u32: func(c *Config) *uint32 { return &c.CMIN }},
+	{name: "ISTEP", addr: RegISTEP, u32: func(c *Config) *uint32 { return &c.ISTEP }},
+	{name: "CVAL", addr: RegCVAL, u32: func(c *Config) *uint32 { return &c.CVAL }},
+	{name: "RLED", addr: RegRLED, u8: func(c *Config) *uint8 { return &c.RLED }},
+	{name: "GLED", addr: RegGLED, u8: func(c *Config) *uint8 { return &c.GLED }},
+	{name: "BLED", addr: RegBLED, u8: func(c *Config) *uint8 { return &c.BLED }},
+	{name: "GP1REG", addr: RegGP1REG, u8: func(c *Config) *uint8 { return &c.GP1REG }, gp: 1},
+	{name: "GP2REG", addr: RegGP2REG, u8: func(c *Config) *uint8 { return &c.GP2REG }, gp: 2},
+	{name: "GP3REG", addr: RegGP3REG, u8: func(c *Config) *uint8 { return &c.GP3REG }, gp: 3},
+	{name: "ANTBOUNC", addr: RegANTBOUNC, u8: func(c *Config) *uint8 { return &c.ANTBOUNC }, unit: antiBounceUnit},
+	{name: "DPPERIOD", addr: RegDPPERIOD, u8: func(c *Config) *uint8 { return &c.DPPERIOD }, unit: doublePushUnit},
+	{name: "FADERGB", addr: RegFADERGB, u8: func(c *Config) *uint8 { return &c.FADERGB }, unit: time.Millisecond},
+	{name: "FADEGP", addr: RegFADEGP, u8: func(c *Config) *uint8 { return &c.FADEGP }, unit: time.Millisecond},
+}
+
+//-----------------------------------------------------------------------------
+
+// gpconf returns the GPxCONF register value for GP pin n.
+func (c *Config) gpconf(n int) uint8 {
+	return [...]uint8{c.GP1CONF, c.GP2CONF, c.GP3CONF}[n-1]
+}
+
+// isInput returns true if the register is the GPxREG register of an input pin.
+// The register holds the pin level or ADC value, so it isn't configuration.
+func (c *Config) isInput(r *configReg) bool {
+	if r.gp == 0 {
+		return false
+	}
+	mode := c.gpconf(r.gp) & gpconfMODE
+	return mode == gpconfIN || mode == gpconfAN
+}
+
+// equal returns true if the register has the same value in c and x.
+func (c *Config) equal(x *Config, r *configReg) bool {
+	if r.u32 != nil {
+		return *r.u32(c) == *r.u32(x)
+	}
+	return *r.u8(c) == *r.u8(x)
+}
+
+// format returns the register value as a string.
+func (c *Config) format(r *configReg) string {
+	switch {
+	case r.u32 != nil:
+		v := *r.u32(c)
+		if c.GCONF&gconfDTYPE != 0 {
+			return fmt.Sprintf("%g", math.Float32frombits(v))
+		}
+		return fmt.Sprintf("%d", int32(v))
+	case r.unit != 0:
+		return (time.Duration(*r.u8(c)) * r.unit).String()
+	case r.hex:
+		return fmt.Sprintf("0x%02x", *r.u8(c))
+	}
+	return fmt.Sprintf("%d", *r.u8(c))
+}
+
+// Diff returns a line for each register that differs between the configurations.
+// GPxREG registers of input pins are ignored.
+func (c *Config) Diff(x *Config) []string {
+	var diff []string
+	for i := range configRegs {
+		r := &configRegs[i]
+		if c.equal(x, r) || (c.isInput(r) && x.isInput(r)) {
+			continue
+		}
+		diff = append(diff, fmt.Sprintf("%s: %s -> %s", r.name, c.format(r), x.format(r)))
+	}
+	return diff
+}
+
+//-----------------------------------------------------------------------------
+
+// ReadConfig returns a snapshot of the encoder configuration registers.
+// It is only supported by the V2 variant.
+func (d *Dev) ReadConfig() (*Config, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.readConfig()
+}
+
+// readConfig reads the configuration registers.
+func (d *Dev) readConfig() (*Config, error) {
+	if d.v.id != VariantV2 {
+		return nil, ErrNotSupported
+	}
+	c := &Config{}
+	for i := range configRegs {
+		r := &configRegs[i]
+		var err error
+		if r.u32 != nil {
+			*r.u32(c), err = d.c.ReadUint32(r.addr)
+		} else {
+			*r.u8(c), err = d.c.ReadUint8(r.addr)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	c.GCONF &^= gconfMBANK | gconfRESET
+	return c, nil
+}
+
+// ApplyConfig writes a configuration to the encoder.
+// Only the registers that differ from the current configuration are written.
+// GPxREG registers of input pins are not written.
+// It is only supported by the V2 variant.
+func (d *Dev) ApplyConfig(c *Config) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	cur, err := d.readConfig()
+	if err != nil {
+		return err
+	}
+	for i := range configRegs {
+		r := &configRegs[i]
+		if cur.equal(c, r) || c.isInput(r) {
+			continue
+		}
+		err := d.wrConfigReg(c, r)
+		if err != nil {
+			return err
+		}
+	}
+	// the LED is written as a whole so the animator base color is kept
+	led := RGB{R: c.RLED, G: c.GLED, B: c.BLED}
+	if led != (RGB{R: cur.RLED, G: cur.GLED, B: cur.BLED}) {
+		return d.setLED(led)
+	}
+	return nil
+}
+
+// wrConfigReg writes a configuration register and updates the register shadows.
+func (d *Dev) wrConfigReg(c *Config, r *configReg) error {
+	switch r.addr {
+	case RegGCONF:
+		return d.wrGCONF((d.gconf & gconfMBANK) | (c.GCONF &^ (gconfMBANK | gconfRESET)))
+	case RegGP1CONF, RegGP2CONF, RegGP3CONF:
+		return d.gp[r.addr-RegGP1CONF].wrConf(*r.u8(c))
+	case RegRLED, RegGLED, RegBLED:
+		return nil
+	case RegCVAL:
+		d.countValid = false
+	case RegISTEP:
+		d.stepWritten()
+	}
+	var err error
+	if r.u32 != nil {
+		err = d.c.WriteUint32(r.addr, *r.u32(c))
+	} else {
+		err = d.c.WriteUint8(r.addr, *r.u8(c))
+	}
+	if err != nil {
+		return err
+	}
+	switch r.addr {
+	case RegGP1REG, RegGP2REG, RegGP3REG:
+		if d.fadeGP != 0 && c.gpconf(r.gp)&gpconfMODE == gpconfPWM {
+			d.startFade(FadeGP1 << uint(r.gp-1))
+		}
+	case RegFADERGB:
+		d.fadeRGB = c.FADERGB
+	case RegFADEGP:
+		d.fadeGP = c.FADEGP
+	}
+	return nil
+}
+
+//-----------------------------------------------------------------------------
@@ -3,12 +3,14 @@
 // that can be found in the LICENSE file.
 
 // Package rei2c is a driver for the I2C Rotary Encoder V2 Driver
+// and the I2C Encoder Mini.
 //
 // Author
 // Jason Harris (https://github.com/deadsy)
 //
 // Datasheet
 // https://github.com/Fattoresaimon/I2CEncoderV2
+// https://github.com/Fattoresaimon/I2CEncoderMini
 //
 // Product Page
 // https://www.kickstarter.com/projects/1351830006/i2c-encoder-v2
@@ -0,0 +1,192 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+//-----------------------------------------------------------------------------
+// EEPROM access
+// The V2 256 byte EEPROM is accessed as 2 banks of 128 bytes mapped at RegEEPROM.
+// The bank is selected with the MBANK bit of GCONF. The Mini EEPROM is a single
+// block mapped at RegMiniEEPROM.
+
+// EEPROMSize is the size of the V2 encoder EEPROM in bytes.
+const EEPROMSize = 256
+
+// eepromReadTime is the time to wait after an EEPROM read.
+const eepromReadTime = 1 * time.Millisecond
+
+// eepromWriteTime is the time to wait for an EEPROM write to complete.
+const eepromWriteTime = 5 * time.Millisecond
+
+// setBank selects the EEPROM bank for an EEPROM address.
+// It returns the register address for the EEPROM location.
+func (d *Dev) setBank(adr uint8) (uint8, error) {
+	if !d.v.banked {
+		return d.v.eeprom + adr, nil
+	}
+	x := d.gconf
+	if adr <= 0x7f {
+		// switch to bank 0
+		x &= ^gconfMBANK
+		adr += RegEEPROM
+	} else {
+		// switch to bank 1
+		x |= gconfMBANK
+	}
+	if x != d.gconf {
+		err := d.wrGCONF(x)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return adr, nil
+}
+
+// checkMem checks that an EEPROM access is within the EEPROM.
+func (d *Dev) checkMem(base uint8, n int) error {
+	if int(base)+n > d.v.eepromSize {
+		return errors.New("rei2c: eeprom access out of range")
+	}
+	return nil
+}
+
+// RdMem reads from the rei2c EEPROM.
+// The bank switching and reads are done as one locked sequence.
+func (d *Dev) RdMem(base uint8, n int) ([]uint8, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rdMem(base, n)
+}
+
+// rdMem reads from the EEPROM.
+func (d *Dev) rdMem(base uint8, n int) ([]uint8, error) {
+	if n == 0 {
+		return nil, nil
+	}
+	if err := d.checkMem(base, n); err != nil {
+		return nil, err
+	}
+	mem := make([]uint8, n)
+	for i := range mem {
+		adr, err := d.setBank(base + uint8(i))
+		if err != nil {
+			return nil, err
+		}
+		val, err := d.c.ReadUint8(adr)
+		if err != nil {
+			return nil, err
+		}
+		mem[i] = val
+		time.Sleep(eepromReadTime)
+	}
+	return mem, nil
+}
+
+// WrMem writes to the rei2c EEPROM.
+// The bank switching and writes are done as one locked sequence.
+func (d *Dev) WrMem(base uint8, buf []uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.wrMem(base, buf)
+}
+
+// wrMem writes to the EEPROM.
+func (d *Dev) wrMem(base uint8, buf []uint8) error {
+	if err := d.checkMem(base, len(buf)); err != nil {
+		return err
+	}
+	for i, val := range buf {
+		adr, err := d.setBank(base + uint8(i))
+		if err != nil {
+			return err
+		}
+		err = d.c.WriteUint8(adr, val)
+		if err != nil {
+			return err
+		}
+		time.Sleep(eepromWriteTime)
+	}
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+
+// EEPROM is the encoder EEPROM. It implements io.ReaderAt and io.WriterAt.
+type EEPROM struct {
+	d *Dev
+}
+
+// EEPROM returns the encoder EEPROM.
+func (d *Dev) EEPROM() *EEPROM {
+	return &EEPROM{d: d}
+}
+
+// Size returns the size of the EEPROM in bytes.
+func (e *EEPROM) Size() int64 {
+	return int64(e.d.v.eepromSize)
+}
+
+// span returns the number of bytes of an access that are within the EEPROM.
+func (e *EEPROM) span(n int, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("rei2c: negative eeprom offset")
+	}
+	size := e.Size()
+	if off >= size {
+		return 0, nil
+	}
+	if off+int64(n) > size {
+		return int(size - off), nil
+	}
+	return n, nil
+}
+
+// ReadAt implements io.ReaderAt.
+func (e *EEPROM) ReadAt(p []byte, off int64) (int, error) {
+	n, err := e.span(len(p), off)
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		mem, err := e.d.RdMem(uint8(off), n)
+		if err != nil {
+			return 0, err
+		}
+		copy(p, mem)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt implements io.WriterAt.
+func (e *EEPROM) WriteAt(p []byte, off int64) (int, error) {
+	n, err := e.span(len(p), off)
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		err := e.d.WrMem(uint8(off), p[:n])
+		if err != nil {
+			return 0, err
+		}
+	}
+	if n < len(p) {
+		return n, errors.New("rei2c: eeprom write past end")
+	}
+	return n, nil
+}
+
+var _ io.ReaderAt = &EEPROM{}
+var _ io.WriterAt = &EEPROM{}
+
+//-----------------------------------------------------------------------------
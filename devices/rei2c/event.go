@@ -0,0 +1,311 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"fmt"
+	"time"
+
+	"periph.io/x/periph/conn/gpio"
+)
+
+//-----------------------------------------------------------------------------
+
+// EventType identifies the kind of encoder event.
+type EventType uint8
+
+// encoder event types
+const (
+	EventInc         EventType = iota // rotated in the increase direction
+	EventDec                          // rotated in the decrease direction
+	EventPress                        // push button has been pressed
+	EventRelease                      // push button has been released
+	EventDoublePush                   // push button has been double pushed
+	EventMax                          // maximum counter value has been reached
+	EventMin                          // minimum counter value has been reached
+	EventGPRise                       // rising edge on a GP pin
+	EventGPFall                       // falling edge on a GP pin
+	EventFadeRGB                      // RGB LED fade has finished
+	EventFadeGP                       // GP pin fade has finished
+	EventError                        // the device could not be read
+	EventLongPress                    // push button has been held for the long press time
+	EventTripleClick                  // push button has been clicked three times
+	EventRepeat                       // push button is being held (hold repeat)
+	EventPressInc                     // rotated in the increase direction with the button held
+	EventPressDec                     // rotated in the decrease direction with the button held
+)
+
+var eventNames = [...]string{
+	EventInc:         "inc",
+	EventDec:         "dec",
+	EventPress:       "press",
+	EventRelease:     "release",
+	EventDoublePush:  "double",
+	EventMax:         "max",
+	EventMin:         "min",
+	EventGPRise:      "rise",
+	EventGPFall:      "fall",
+	EventFadeRGB:     "fade-rgb",
+	EventFadeGP:      "fade-gp",
+	EventError:       "error",
+	EventLongPress:   "long",
+	EventTripleClick: "triple",
+	EventRepeat:      "repeat",
+	EventPressInc:    "press-inc",
+	EventPressDec:    "press-dec",
+}
+
+func (t EventType) String() string {
+	if int(t) < len(eventNames) {
+		return eventNames[t]
+	}
+	return fmt.Sprintf("EventType(%d)", t)
+}
+
+// Event is an encoder event.
+type Event struct {
+	Type  EventType
+	Count int32     // counter value for EventInc, EventDec, EventPressInc and EventPressDec
+	Value float32   // floating point counter value for the rotation events
+	Pin   int       // GP pin number for EventGPRise, EventGPFall and EventFadeGP
+	Err   error     // bus error for EventError
+	Time  time.Time // time the event was read from the device
+}
+
+func (e Event) String() string {
+	switch e.Type {
+	case EventInc, EventDec, EventPressInc, EventPressDec:
+		if e.Value != 0 {
+			return fmt.Sprintf("%s %g", e.Type, e.Value)
+		}
+		return fmt.Sprintf("%s %d", e.Type, e.Count)
+	case EventGPRise, EventGPFall, EventFadeGP:
+		return fmt.Sprintf("%s GP%d", e.Type, e.Pin)
+	case EventError:
+		return fmt.Sprintf("%s %s", e.Type, e.Err)
+	}
+	return e.Type.String()
+}
+
+//-----------------------------------------------------------------------------
+
+// eventBufferSize is the number of events buffered on the event channel.
+const eventBufferSize = 16
+
+// Events returns the channel on which encoder events are delivered.
+// The channel is closed when the device is halted.
+func (d *Dev) Events() <-chan Event {
+	return d.events
+}
+
+// run is the event loop goroutine. It services the device until halted.
+func (d *Dev) run() {
+	defer d.wg.Done()
+	if d.opts.IntPin != nil {
+		runInt(d.opts.IntPin, d.opts.pollPeriod(), d.done, d.tick, d.poll)
+	} else {
+		d.runPoll()
+	}
+}
+
+// runPoll polls the device status at the poll period.
+func (d *Dev) runPoll() {
+	t := time.NewTicker(d.opts.pollPeriod())
+	defer t.Stop()
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-t.C:
+			d.poll()
+			d.tick()
+		}
+	}
+}
+
+// tick runs the timed parts of the event loop.
+func (d *Dev) tick() {
+	d.gestureTick(time.Now())
+}
+
+// runInt waits for an INT pin to be asserted and then calls poll.
+// The INT pin stays low until the status registers have been read, so the pin
+// level is checked as well as waiting for an edge. The poll period is used as
+// the edge timeout so a halt is noticed promptly. tick is called each time
+// the loop wakes up.
+func runInt(pin gpio.PinIn, period time.Duration, done <-chan struct{}, tick func(), poll func() error) {
+	for {
+		if pin.Read() == gpio.High {
+			pin.WaitForEdge(period)
+		}
+		select {
+		case <-done:
+			return
+		default:
+		}
+		tick()
+		if pin.Read() == gpio.Low {
+			err := poll()
+			if err != nil || pin.Read() == gpio.Low {
+				// don't spin on a bus error, or on INT held low by
+				// something the poll doesn't clear
+				select {
+				case <-done:
+					return
+				case <-time.After(period):
+				}
+			}
+		}
+	}
+}
+
+// poll reads the encoder status and sends the resulting events.
+// The events are sent after the device lock is released, so a slow reader of
+// the event channel doesn't hold up other users of the device.
+// Bus errors are reported with an EventError and returned.
+func (d *Dev) poll() error {
+	d.mu.Lock()
+	ev, err := d.rdEvents()
+	d.mu.Unlock()
+	for _, e := range ev {
+		d.send(e)
+	}
+	return err
+}
+
+// rdEvents reads the encoder status and returns the resulting events.
+func (d *Dev) rdEvents() ([]Event, error) {
+	status, err := d.rdESTATUS()
+	if err != nil {
+		return []Event{errorEvent(err)}, err
+	}
+	if status == 0 {
+		return nil, nil
+	}
+	var ev []Event
+	now := time.Now()
+	if status&statusPUSHP != 0 {
+		ev = append(ev, Event{Type: EventPress, Time: now})
+		if err := d.gesturePress(now); err != nil {
+			ev = append(ev, errorEvent(err))
+		}
+	}
+	if status&statusPUSHR != 0 {
+		ev = append(ev, Event{Type: EventRelease, Time: now})
+		triple, err := d.gestureRelease(now)
+		if err != nil {
+			ev = append(ev, errorEvent(err))
+		}
+		if triple {
+			ev = append(ev, Event{Type: EventTripleClick, Time: now})
+		}
+	}
+	if status&statusPUSHD != 0 {
+		ev = append(ev, Event{Type: EventDoublePush, Time: now})
+	}
+	if status&(statusRINC|statusRDEC) != 0 {
+		e := Event{Time: now}
+		if d.isFloat() {
+			e.Value, err = d.rdFloatReg(d.v.cval)
+		} else {
+			e.Count, err = d.rdCounterReg(d.v.cval)
+			if err == nil && d.opts.Accel != nil {
+				e.Count, status, err = d.accelerate(status, e.Count, now)
+			}
+		}
+		if err != nil {
+			return append(ev, errorEvent(err)), err
+		}
+		val := float64(e.Count)
+		if d.isFloat() {
+			val = float64(e.Value)
+		}
+		if err := d.showCounter(val); err != nil {
+			ev = append(ev, errorEvent(err))
+		}
+		if status&statusRINC != 0 {
+			e.Type = d.gestureRotate(EventInc)
+			ev = append(ev, e)
+		}
+		if status&statusRDEC != 0 {
+			e.Type = d.gestureRotate(EventDec)
+			ev = append(ev, e)
+		}
+	}
+	if status&statusRMAX != 0 {
+		ev = append(ev, Event{Type: EventMax, Time: now})
+	}
+	if status&statusRMIN != 0 {
+		ev = append(ev, Event{Type: EventMin, Time: now})
+	}
+	if status&statusINT2 != 0 {
+		return d.rdEvents2(ev, now)
+	}
+	return ev, nil
+}
+
+// rdEvents2 reads the secondary interrupt status and adds the resulting events.
+func (d *Dev) rdEvents2(ev []Event, now time.Time) ([]Event, error) {
+	i2status, err := d.c.ReadUint8(RegI2STATUS)
+	if err != nil {
+		return append(ev, errorEvent(err)), err
+	}
+	// GP pin edges
+	for i := range d.gp {
+		p := &d.gp[i]
+		if i2status&(i2statusGP1POS<<uint(2*i)) != 0 {
+			p.signalEdge()
+			ev = append(ev, Event{Type: EventGPRise, Pin: p.n, Time: now})
+		}
+		if i2status&(i2statusGP1NEG<<uint(2*i)) != 0 {
+			p.signalEdge()
+			ev = append(ev, Event{Type: EventGPFall, Pin: p.n, Time: now})
+		}
+	}
+	// fade completion
+	if i2status&i2statusFADE != 0 {
+		fstatus, err := d.c.ReadUint8(RegFSTATUS)
+		if err != nil {
+			return append(ev, errorEvent(err)), err
+		}
+		// only the fades recorded by startFade are reported
+		done := d.fading &^ fstatus
+		d.fading &= fstatus
+		if done&fstatusRGB != 0 {
+			ev = append(ev, Event{Type: EventFadeRGB, Time: now})
+		}
+		for i := range d.gp {
+			if done&(fstatusGP1<<uint(i)) != 0 {
+				ev = append(ev, Event{Type: EventFadeGP, Pin: i + 1, Time: now})
+			}
+		}
+	}
+	return ev, nil
+}
+
+// errorEvent returns an EventError for a bus error.
+func errorEvent(err error) Event {
+	return Event{Type: EventError, Err: err, Time: time.Now()}
+}
+
+// sendError sends an EventError for a bus error.
+func (d *Dev) sendError(err error) bool {
+	return d.send(errorEvent(err))
+}
+
+// send an event to the event channel. It returns false if the device was halted.
+// Any LED animation triggered by the event is started.
+func (d *Dev) send(e Event) bool {
+	d.anim.trigger(e.Type)
+	select {
+	case d.events <- e:
+		return true
+	case <-d.done:
+		return false
+	}
+}
+
+//-----------------------------------------------------------------------------
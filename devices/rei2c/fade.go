@@ -0,0 +1,150 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"errors"
+	"time"
+)
+
+//-----------------------------------------------------------------------------
+// LED fading
+// When a fade timer is non-zero the device steps the LED (or GP pin PWM)
+// intensity towards a newly written value by one unit each timer period.
+
+// FadeMask is a set of fading outputs (the FSTATUS register).
+type FadeMask uint8
+
+// FSTATUS bits
+const (
+	FadeRed   = FadeMask(fstatusR)   // red LED
+	FadeGreen = FadeMask(fstatusG)   // green LED
+	FadeBlue  = FadeMask(fstatusB)   // blue LED
+	FadeGP1   = FadeMask(fstatusGP1) // GP1 pin
+	FadeGP2   = FadeMask(fstatusGP2) // GP2 pin
+	FadeGP3   = FadeMask(fstatusGP3) // GP3 pin
+	FadeRGB   = FadeRed | FadeGreen | FadeBlue
+	FadeGP    = FadeGP1 | FadeGP2 | FadeGP3
+	FadeAll   = FadeRGB | FadeGP
+)
+
+// maxFadeStep is the maximum fade timer period.
+const maxFadeStep = 255 * time.Millisecond
+
+// fadeReg converts a fade step period to a fade timer register value.
+func fadeReg(t time.Duration) (uint8, error) {
+	if t < 0 || t > maxFadeStep {
+		return 0, errors.New("rei2c: fade step period out of range")
+	}
+	return uint8(t / time.Millisecond), nil
+}
+
+// SetFadeRGB sets the step period (1ms resolution, 0..255ms) for RGB LED fades.
+// 0 disables fading.
+func (d *Dev) SetFadeRGB(t time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.v.led {
+		return ErrNotSupported
+	}
+	val, err := fadeReg(t)
+	if err != nil {
+		return err
+	}
+	return d.wrFadeRGB(val)
+}
+
+// wrFadeRGB writes the RGB LED fade timer.
+func (d *Dev) wrFadeRGB(val uint8) error {
+	err := d.c.WriteUint8(RegFADERGB, val)
+	if err != nil {
+		return err
+	}
+	d.fadeRGB = val
+	return nil
+}
+
+// SetFadeGP sets the step period (1ms resolution, 0..255ms) for GP pin PWM fades.
+// 0 disables fading.
+func (d *Dev) SetFadeGP(t time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.v.led {
+		return ErrNotSupported
+	}
+	val, err := fadeReg(t)
+	if err != nil {
+		return err
+	}
+	err = d.c.WriteUint8(RegFADEGP, val)
+	if err != nil {
+		return err
+	}
+	d.fadeGP = val
+	return nil
+}
+
+// FadeLED starts a fade of the RGB LED to a target color.
+// The fade step period is set first if it has changed.
+func (d *Dev) FadeLED(rgb RGB, step time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.v.led {
+		return ErrNotSupported
+	}
+	val, err := fadeReg(step)
+	if err != nil {
+		return err
+	}
+	if val != d.fadeRGB {
+		err := d.wrFadeRGB(val)
+		if err != nil {
+			return err
+		}
+	}
+	return d.setLED(rgb)
+}
+
+// Fading returns the set of outputs with a fade in progress.
+func (d *Dev) Fading() (FadeMask, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.v.led {
+		return 0, ErrNotSupported
+	}
+	val, err := d.c.ReadUint8(RegFSTATUS)
+	return FadeMask(val), err
+}
+
+// WaitFade waits for the fades in progress on the outputs in m to finish.
+// Use a negative timeout to wait forever.
+func (d *Dev) WaitFade(m FadeMask, timeout time.Duration) error {
+	start := time.Now()
+	for {
+		f, err := d.Fading()
+		if err != nil {
+			return err
+		}
+		if f&m == 0 {
+			return nil
+		}
+		if timeout >= 0 && time.Since(start) >= timeout {
+			return errors.New("rei2c: timeout waiting for fade")
+		}
+		time.Sleep(fadePollPeriod)
+	}
+}
+
+// fadePollPeriod is the FSTATUS polling period for WaitFade.
+const fadePollPeriod = 5 * time.Millisecond
+
+// startFade records the outputs with a fade started by a register write.
+// A write that doesn't change the register value starts no fade.
+func (d *Dev) startFade(m FadeMask) {
+	d.fading |= uint8(m)
+}
+
+//-----------------------------------------------------------------------------
@@ -0,0 +1,104 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+//-----------------------------------------------------------------------------
+// general configuration
+
+// Direction is the encoder rotation direction that increments the counter.
+type Direction uint8
+
+// encoder directions
+const (
+	DirCW  Direction = iota // clockwise rotation increments the counter
+	DirCCW                  // counter-clockwise rotation increments the counter
+)
+
+// ReadMode is the encoder reading mode.
+type ReadMode uint8
+
+// encoder reading modes
+const (
+	ReadX1 ReadMode = iota // count on one edge of the encoder signal
+	ReadX2                 // count on both edges of the encoder signal
+)
+
+// GeneralConfig is the general configuration of the encoder (the GCONF register).
+type GeneralConfig struct {
+	// RGB enables an illuminated RGB rotary encoder.
+	RGB bool
+	// Float selects floating point counter registers (CVAL, CMAX, CMIN and ISTEP).
+	// Use the Counter*Float functions to access the counter in this mode.
+	Float bool
+	// Wrap enables counter wrap between the minimum and maximum values.
+	Wrap bool
+	// Direction is the rotation direction that increments the counter.
+	Direction Direction
+	// DisableIntPullUp disables the internal pull-up on the INT pin.
+	DisableIntPullUp bool
+	// ReadMode is the encoder reading mode.
+	ReadMode ReadMode
+}
+
+// gconf returns the GCONF register value for the configuration.
+func (g *GeneralConfig) gconf() uint8 {
+	var gconf uint8
+	if g.RGB {
+		// enable an illuminated RGB encoder.
+		gconf |= gconfETYPE
+	}
+	if g.Float {
+		// use floating point counter registers.
+		gconf |= gconfDTYPE
+	}
+	if g.Wrap {
+		gconf |= gconfWRAPE
+	}
+	if g.Direction == DirCCW {
+		gconf |= gconfDIRE
+	}
+	if g.DisableIntPullUp {
+		gconf |= gconfIPUD
+	}
+	if g.ReadMode == ReadX2 {
+		gconf |= gconfRMOD
+	}
+	return gconf
+}
+
+// generalConfig returns the configuration for a GCONF register value.
+func generalConfig(gconf uint8) GeneralConfig {
+	g := GeneralConfig{
+		RGB:              gconf&gconfETYPE != 0,
+		Float:            gconf&gconfDTYPE != 0,
+		Wrap:             gconf&gconfWRAPE != 0,
+		DisableIntPullUp: gconf&gconfIPUD != 0,
+	}
+	if gconf&gconfDIRE != 0 {
+		g.Direction = DirCCW
+	}
+	if gconf&gconfRMOD != 0 {
+		g.ReadMode = ReadX2
+	}
+	return g
+}
+
+// GeneralConfig returns the general configuration of the encoder.
+func (d *Dev) GeneralConfig() GeneralConfig {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return generalConfig(d.gconf)
+}
+
+// SetGeneralConfig sets the general configuration of the encoder.
+// The EEPROM bank selection is preserved.
+func (d *Dev) SetGeneralConfig(g GeneralConfig) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.wrGCONF((d.gconf & gconfMBANK) | g.gconf())
+}
+
+//-----------------------------------------------------------------------------
@@ -0,0 +1,180 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"math"
+	"time"
+)
+
+//-----------------------------------------------------------------------------
+// push button gestures
+// Gestures are built from the press and release events reported by the
+// device. Long press and hold repeat are timed by the event loop, so their
+// resolution is the poll period.
+
+// Gestures specifies the push button gesture timings.
+type Gestures struct {
+	// LongPress is the hold time for an EventLongPress. 0 is disabled.
+	LongPress time.Duration
+	// MultiClick is the maximum time between the clicks of an EventTripleClick. 0 is disabled.
+	MultiClick time.Duration
+	// RepeatDelay is the hold time before the first EventRepeat. 0 is disabled.
+	RepeatDelay time.Duration
+	// RepeatRate is the period of EventRepeat while the button is held. 0 is RepeatDelay.
+	RepeatRate time.Duration
+	// PressStep is the counter step multiplier while the button is held. 0 is no change.
+	PressStep int32
+}
+
+func (g *Gestures) repeatRate() time.Duration {
+	if g.RepeatRate <= 0 {
+		return g.RepeatDelay // default
+	}
+	return g.RepeatRate
+}
+
+// gestureState is the push button gesture state.
+// scaled and step are protected by the device lock.
+type gestureState struct {
+	pressed bool      // the button is held down
+	start   time.Time // press time
+	rotated bool      // the encoder was rotated while pressed
+	held    bool      // a long press or repeat was sent while pressed
+	long    bool      // a long press was sent while pressed
+	repeat  time.Time // time of the next repeat
+	clicks  int       // number of clicks in a multi click
+	last    time.Time // time of the last click
+	scaled  bool      // the counter step has been scaled
+	step    uint32    // ISTEP register value before scaling
+}
+
+//-----------------------------------------------------------------------------
+
+// gesturePress handles a push button press.
+func (d *Dev) gesturePress(now time.Time) error {
+	g := d.opts.Gestures
+	if g == nil {
+		return nil
+	}
+	s := &d.gest
+	s.pressed = true
+	s.start = now
+	s.rotated = false
+	s.held = false
+	s.long = false
+	s.repeat = now.Add(g.RepeatDelay)
+	if g.PressStep == 0 || s.scaled {
+		return nil
+	}
+	// scale the counter step while the button is held
+	step, err := d.c.ReadUint32(d.v.istep)
+	if err != nil {
+		return err
+	}
+	x := uint32(int32(step) * g.PressStep)
+	if d.isFloat() {
+		x = math.Float32bits(math.Float32frombits(step) * float32(g.PressStep))
+	}
+	err = d.c.WriteUint32(d.v.istep, x)
+	if err != nil {
+		return err
+	}
+	s.step = step
+	s.scaled = true
+	return nil
+}
+
+// gestureRelease handles a push button release.
+// It returns true if the release completes a triple click.
+func (d *Dev) gestureRelease(now time.Time) (bool, error) {
+	g := d.opts.Gestures
+	if g == nil {
+		return false, nil
+	}
+	s := &d.gest
+	click := s.pressed && !s.rotated && !s.held
+	triple := false
+	s.pressed = false
+	// triple click
+	if g.MultiClick > 0 {
+		if !click || (s.clicks > 0 && now.Sub(s.last) > g.MultiClick) {
+			s.clicks = 0
+		}
+		if click {
+			s.clicks++
+			s.last = now
+			if s.clicks == 3 {
+				s.clicks = 0
+				triple = true
+			}
+		}
+	}
+	// restore the counter step
+	err := d.restoreStep()
+	return triple, err
+}
+
+// restoreStep writes back the counter step scaled by a press.
+func (d *Dev) restoreStep() error {
+	s := &d.gest
+	if !s.scaled {
+		return nil
+	}
+	err := d.c.WriteUint32(d.v.istep, s.step)
+	if err != nil {
+		return err
+	}
+	s.scaled = false
+	return nil
+}
+
+// stepWritten is called when the counter step is written.
+// The written step replaces a scaled step and is kept on release.
+func (d *Dev) stepWritten() {
+	d.gest.scaled = false
+}
+
+// gestureRotate handles a rotation. It returns the event type for the rotation.
+func (d *Dev) gestureRotate(t EventType) EventType {
+	if d.opts.Gestures == nil || !d.gest.pressed {
+		return t
+	}
+	d.gest.rotated = true
+	if t == EventInc {
+		return EventPressInc
+	}
+	return EventPressDec
+}
+
+// gestureTick sends the timed gestures for a held button.
+// It is called by the event loop at the poll period without the device lock.
+// The gesture state is only used by the event loop.
+func (d *Dev) gestureTick(now time.Time) {
+	g := d.opts.Gestures
+	s := &d.gest
+	if g == nil || !s.pressed || s.rotated {
+		return
+	}
+	// long press
+	if g.LongPress > 0 && !s.long && now.Sub(s.start) >= g.LongPress {
+		s.long = true
+		s.held = true
+		d.send(Event{Type: EventLongPress, Time: now})
+	}
+	// hold repeat
+	if g.RepeatDelay > 0 && !now.Before(s.repeat) {
+		s.held = true
+		s.repeat = s.repeat.Add(g.repeatRate())
+		if s.repeat.Before(now) {
+			// don't burst after a slow event loop
+			s.repeat = now.Add(g.repeatRate())
+		}
+		d.send(Event{Type: EventRepeat, Time: now})
+	}
+}
+
+//-----------------------------------------------------------------------------
@@ -0,0 +1,370 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"periph.io/x/periph/conn/gpio"
+	"periph.io/x/periph/conn/physic"
+)
+
+//-----------------------------------------------------------------------------
+
+// gpconf bits
+const (
+	gpconfMODE   = uint8(3 << 0) // GP pin mode
+	gpconfPWM    = uint8(0 << 0) // PWM output
+	gpconfOUT    = uint8(1 << 0) // Push-pull output
+	gpconfAN     = uint8(2 << 0) // Analog input
+	gpconfIN     = uint8(3 << 0) // Digital input
+	gpconfPULLEN = uint8(1 << 2) // Pull-up enable
+	gpconfINTPE  = uint8(1 << 3) // Interrupt on positive edge
+	gpconfINTNE  = uint8(1 << 4) // Interrupt on negative edge
+	gpconfINT    = gpconfINTPE | gpconfINTNE
+)
+
+// NumGP is the number of general purpose pins on the encoder.
+const NumGP = 3
+
+//-----------------------------------------------------------------------------
+
+// GPPin is a general purpose pin (GP1, GP2 or GP3) on the encoder board.
+type GPPin struct {
+	d    *Dev
+	n    int           // pin number 1..NumGP
+	conf uint8         // GPxCONF register value
+	edge chan struct{} // signalled by the event loop on an edge interrupt
+}
+
+// GP returns the general purpose pin n (1..NumGP).
+// It returns nil for an invalid pin number or if the variant has no GP pins.
+func (d *Dev) GP(n int) *GPPin {
+	if n < 1 || n > d.v.numGP {
+		return nil
+	}
+	return &d.gp[n-1]
+}
+
+// confReg returns the GPxCONF register address.
+func (p *GPPin) confReg() uint8 {
+	return RegGP1CONF + uint8(p.n-1)
+}
+
+// dataReg returns the GPxREG register address.
+func (p *GPPin) dataReg() uint8 {
+	return RegGP1REG + uint8(p.n-1)
+}
+
+// wrConf writes the GPxCONF register if it has changed.
+func (p *GPPin) wrConf(conf uint8) error {
+	if conf == p.conf {
+		return nil
+	}
+	err := p.d.c.WriteUint8(p.confReg(), conf)
+	if err != nil {
+		return err
+	}
+	p.conf = conf
+	return nil
+}
+
+// mode returns the current pin mode.
+func (p *GPPin) mode() uint8 {
+	return p.conf & gpconfMODE
+}
+
+//-----------------------------------------------------------------------------
+// pin.Pin
+
+func (p *GPPin) String() string {
+	return fmt.Sprintf("%s.%s", p.d, p.Name())
+}
+
+// Halt implements conn.Resource.
+func (p *GPPin) Halt() error {
+	return nil
+}
+
+// Name returns the name of the pin.
+func (p *GPPin) Name() string {
+	return fmt.Sprintf("GP%d", p.n)
+}
+
+// Number returns the pin number (1..NumGP).
+func (p *GPPin) Number() int {
+	return p.n
+}
+
+// Function returns the current pin function.
+func (p *GPPin) Function() string {
+	p.d.mu.Lock()
+	defer p.d.mu.Unlock()
+	switch p.mode() {
+	case gpconfPWM:
+		return "PWM"
+	case gpconfOUT:
+		return "Out"
+	case gpconfAN:
+		return "ADC"
+	default:
+		return "In"
+	}
+}
+
+//-----------------------------------------------------------------------------
+// gpio.PinIn
+
+// In sets the pin as a digital input.
+// The GP pins have an optional pull-up, pull-down is not supported.
+// Edge interrupts are reported by the device on the INT pin and are read
+// by the event loop, so the INTCONF mask must include IntINT2.
+func (p *GPPin) In(pull gpio.Pull, edge gpio.Edge) error {
+	p.d.mu.Lock()
+	defer p.d.mu.Unlock()
+	conf := (p.conf &^ (gpconfMODE | gpconfINT)) | gpconfIN
+	switch pull {
+	case gpio.PullNoChange:
+	case gpio.PullUp:
+		conf |= gpconfPULLEN
+	case gpio.Float:
+		conf &^= gpconfPULLEN
+	default:
+		return errors.New("rei2c: pull-down is not supported")
+	}
+	switch edge {
+	case gpio.NoEdge:
+	case gpio.RisingEdge:
+		conf |= gpconfINTPE
+	case gpio.FallingEdge:
+		conf |= gpconfINTNE
+	case gpio.BothEdges:
+		conf |= gpconfINT
+	default:
+		return errors.New("rei2c: invalid edge")
+	}
+	err := p.wrConf(conf)
+	if err != nil {
+		return err
+	}
+	// discard any accumulated edge
+	select {
+	case <-p.edge:
+	default:
+	}
+	return nil
+}
+
+// Read returns the current pin level.
+func (p *GPPin) Read() gpio.Level {
+	p.d.mu.Lock()
+	defer p.d.mu.Unlock()
+	val, err := p.d.c.ReadUint8(p.dataReg())
+	if err != nil {
+		return gpio.Low
+	}
+	return val != 0
+}
+
+// WaitForEdge waits for an edge interrupt on the pin.
+// Use a negative timeout to wait forever.
+// It returns false on timeout or if the device is halted.
+func (p *GPPin) WaitForEdge(timeout time.Duration) bool {
+	// an edge may have occurred since the last call
+	select {
+	case <-p.edge:
+		return true
+	default:
+	}
+	var t <-chan time.Time
+	if timeout >= 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		t = timer.C
+	}
+	select {
+	case <-p.edge:
+		return true
+	case <-t:
+		return false
+	case <-p.d.done:
+		return false
+	}
+}
+
+// signalEdge records an edge interrupt for WaitForEdge.
+func (p *GPPin) signalEdge() {
+	select {
+	case p.edge <- struct{}{}:
+	default:
+	}
+}
+
+// Pull returns the pull-up setting if the pin is a digital input.
+func (p *GPPin) Pull() gpio.Pull {
+	p.d.mu.Lock()
+	defer p.d.mu.Unlock()
+	if p.mode() != gpconfIN {
+		return gpio.PullNoChange
+	}
+	if p.conf&gpconfPULLEN != 0 {
+		return gpio.PullUp
+	}
+	return gpio.Float
+}
+
+// DefaultPull returns the pull setting after device reset.
+func (p *GPPin) DefaultPull() gpio.Pull {
+	return gpio.Float
+}
+
+//-----------------------------------------------------------------------------
+// gpio.PinOut
+
+// Out sets the pin as a push-pull output with the given level.
+func (p *GPPin) Out(l gpio.Level) error {
+	p.d.mu.Lock()
+	defer p.d.mu.Unlock()
+	err := p.wrConf((p.conf &^ (gpconfMODE | gpconfINT)) | gpconfOUT)
+	if err != nil {
+		return err
+	}
+	var val uint8
+	if l {
+		val = 1
+	}
+	return p.d.c.WriteUint8(p.dataReg(), val)
+}
+
+// PWM sets the pin as a PWM output with the given duty cycle.
+// The duty cycle has 8-bit resolution. The PWM frequency is fixed by the
+// device so f must be 0. If the GP fade timer is set the output fades to
+// the new duty cycle.
+func (p *GPPin) PWM(duty gpio.Duty, f physic.Frequency) error {
+	p.d.mu.Lock()
+	defer p.d.mu.Unlock()
+	if !duty.Valid() {
+		return errors.New("rei2c: invalid pwm duty cycle")
+	}
+	if f != 0 {
+		return errors.New("rei2c: pwm frequency is not configurable")
+	}
+	err := p.wrConf((p.conf &^ (gpconfMODE | gpconfINT)) | gpconfPWM)
+	if err != nil {
+		return err
+	}
+	val := dutyToReg(duty)
+	// the device only fades when the duty cycle changes
+	fade := false
+	if p.d.fadeGP != 0 {
+		cur, err := p.d.c.ReadUint8(p.dataReg())
+		if err != nil {
+			return err
+		}
+		fade = cur != val
+	}
+	err = p.d.c.WriteUint8(p.dataReg(), val)
+	if err != nil {
+		return err
+	}
+	if fade {
+		p.d.startFade(FadeGP1 << uint(p.n-1))
+	}
+	return nil
+}
+
+// dutyToReg converts a periph duty cycle to a 0..255 register value.
+func dutyToReg(duty gpio.Duty) uint8 {
+	return uint8((int64(duty)*255 + int64(gpio.DutyMax)/2) / int64(gpio.DutyMax))
+}
+
+//-----------------------------------------------------------------------------
+// analog input
+
+// Sample is an analog sample.
+type Sample struct {
+	V   physic.ElectricPotential // sampled voltage
+	Raw int32                    // raw ADC value
+}
+
+// adcMax is the maximum raw ADC value (8-bit resolution).
+const adcMax = 255
+
+// ADCPin is a GP pin used as an analog input.
+type ADCPin struct {
+	p *GPPin
+}
+
+// ADC sets the pin as an analog input and returns the ADC reader.
+func (p *GPPin) ADC() (*ADCPin, error) {
+	p.d.mu.Lock()
+	defer p.d.mu.Unlock()
+	err := p.wrConf((p.conf &^ (gpconfMODE | gpconfPULLEN | gpconfINT)) | gpconfAN)
+	if err != nil {
+		return nil, err
+	}
+	return &ADCPin{p: p}, nil
+}
+
+func (a *ADCPin) String() string {
+	return a.p.String()
+}
+
+// Halt implements conn.Resource.
+func (a *ADCPin) Halt() error {
+	return nil
+}
+
+// Name returns the name of the pin.
+func (a *ADCPin) Name() string {
+	return a.p.Name()
+}
+
+// Number returns the pin number (1..NumGP).
+func (a *ADCPin) Number() int {
+	return a.p.Number()
+}
+
+// Function returns the current pin function.
+func (a *ADCPin) Function() string {
+	return a.p.Function()
+}
+
+// Range returns the minimum and maximum samples of the ADC.
+func (a *ADCPin) Range() (Sample, Sample) {
+	return a.sample(0), a.sample(adcMax)
+}
+
+// Read samples the analog input.
+func (a *ADCPin) Read() (Sample, error) {
+	a.p.d.mu.Lock()
+	defer a.p.d.mu.Unlock()
+	if a.p.mode() != gpconfAN {
+		return Sample{}, errors.New("rei2c: pin is not an analog input")
+	}
+	val, err := a.p.d.c.ReadUint8(a.p.dataReg())
+	if err != nil {
+		return Sample{}, err
+	}
+	return a.sample(int32(val)), nil
+}
+
+// sample converts a raw ADC value to a sample.
+func (a *ADCPin) sample(raw int32) Sample {
+	vref := a.p.d.opts.vref()
+	return Sample{
+		V:   vref * physic.ElectricPotential(raw) / adcMax,
+		Raw: raw,
+	}
+}
+
+//-----------------------------------------------------------------------------
+
+var _ gpio.PinIO = &GPPin{}
+
+//-----------------------------------------------------------------------------
@@ -0,0 +1,233 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"periph.io/x/periph/conn/gpio"
+	"periph.io/x/periph/conn/i2c"
+	"periph.io/x/periph/conn/mmr"
+)
+
+//-----------------------------------------------------------------------------
+// bus scan
+
+// Scan probes the I2C addresses first..last for encoders and returns the
+// addresses of the encoders found.
+// An address is probed with the same reset and default register check used
+// when an encoder is opened. Any device that acknowledges a read has the
+// GCONF reset bit written, so the address range should only hold encoders.
+func Scan(b i2c.Bus, first, last uint16) []uint16 {
+	var addrs []uint16
+	for addr := first; addr <= last; addr++ {
+		d := &Dev{c: mmr.Dev8{Conn: &i2c.Dev{Bus: b, Addr: addr}, Order: binary.BigEndian}}
+		// the variant is identified with a read, so nothing is written
+		// to an address without a device
+		if err := d.identify(VariantAuto); err != nil {
+			continue
+		}
+		if d.probe() == nil {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+//-----------------------------------------------------------------------------
+
+// ManagerOpts specifies the multi-encoder manager options.
+type ManagerOpts struct {
+	// First and Last are the I²C address range to scan. They must be set.
+	// Any device in the range that acknowledges a read has the GCONF reset
+	// bit written, so the range should only hold encoders.
+	First, Last uint16
+	// Names are the logical names of the encoders by I²C address.
+	// An encoder without a name is named by its address.
+	Names map[uint16]string
+	// Opts are the options for each encoder. I2CAddr and IntPin are not used.
+	Opts Opts
+	// IntPin is an optional input connected to the wired-OR INT outputs of the encoders.
+	// When INT is asserted the status of every encoder is read.
+	// If nil each encoder is polled.
+	IntPin gpio.PinIn
+}
+
+func (o *ManagerOpts) scanRange() (uint16, uint16, error) {
+	if o.First == 0 || o.Last == 0 {
+		return 0, 0, errors.New("rei2c: manager address range not set")
+	}
+	if o.First > o.Last || o.Last > 0x7f {
+		return 0, 0, fmt.Errorf("rei2c: bad manager address range 0x%02x..0x%02x", o.First, o.Last)
+	}
+	return o.First, o.Last, nil
+}
+
+// ManagerEvent is an encoder event tagged with the encoder address and name.
+type ManagerEvent struct {
+	Event
+	Addr uint16 // encoder I²C address
+	Name string // encoder name
+}
+
+func (e ManagerEvent) String() string {
+	return fmt.Sprintf("%s: %s", e.Name, e.Event)
+}
+
+// Manager is a set of encoders on an I2C bus with a merged event stream.
+type Manager struct {
+	devs   []*Dev
+	addrs  []uint16
+	names  []string
+	events chan ManagerEvent
+	done   chan struct{}  // closed to stop the event loop
+	halt   sync.Once      // halt only once
+	loop   sync.WaitGroup // shared INT event loop
+	fwd    sync.WaitGroup // event forwarders
+}
+
+// NewManager scans an I2C bus for encoders and opens every encoder found.
+// The address range is scanned as with Scan.
+func NewManager(b i2c.Bus, opts *ManagerOpts) (*Manager, error) {
+	if opts == nil {
+		opts = &ManagerOpts{}
+	}
+	first, last, err := opts.scanRange()
+	if err != nil {
+		return nil, err
+	}
+	m := &Manager{
+		events: make(chan ManagerEvent, eventBufferSize),
+		done:   make(chan struct{}),
+	}
+	dopts := opts.Opts
+	dopts.IntPin = nil
+	for addr := first; addr <= last; addr++ {
+		dopts.I2CAddr = addr
+		d, err := makeDev(&i2c.Dev{Bus: b, Addr: addr}, &dopts)
+		if _, ok := err.(*ProbeError); ok {
+			continue // not an encoder
+		}
+		if err != nil {
+			m.halt0()
+			return nil, fmt.Errorf("rei2c: can't open encoder at 0x%02x: %s", addr, err)
+		}
+		name, ok := opts.Names[addr]
+		if !ok {
+			name = fmt.Sprintf("0x%02x", addr)
+		}
+		d.managed = true
+		m.devs = append(m.devs, d)
+		m.addrs = append(m.addrs, addr)
+		m.names = append(m.names, name)
+	}
+	if len(m.devs) == 0 {
+		return nil, errors.New("rei2c: no encoders found")
+	}
+	// setup the shared interrupt pin
+	if opts.IntPin != nil {
+		if err := opts.IntPin.In(gpio.PullUp, gpio.FallingEdge); err != nil {
+			m.halt0()
+			return nil, err
+		}
+	}
+	// start the encoders and the event forwarders
+	for i, d := range m.devs {
+		d.start(opts.IntPin == nil)
+		m.fwd.Add(1)
+		go m.forward(i)
+	}
+	// the manager services the encoders on a shared INT pin
+	if opts.IntPin != nil {
+		m.loop.Add(1)
+		go m.run(opts.IntPin, dopts.pollPeriod())
+	}
+	return m, nil
+}
+
+// Devs returns the encoders in address order.
+// The encoders are halted with the manager, Dev.Halt returns an error.
+func (m *Manager) Devs() []*Dev {
+	return m.devs
+}
+
+// Dev returns the encoder with a logical name, or nil if there is no such encoder.
+func (m *Manager) Dev(name string) *Dev {
+	for i, n := range m.names {
+		if n == name {
+			return m.devs[i]
+		}
+	}
+	return nil
+}
+
+// Events returns the channel on which the events of all encoders are delivered.
+// The channel is closed when the manager is halted.
+func (m *Manager) Events() <-chan ManagerEvent {
+	return m.events
+}
+
+// Halt halts all the encoders and closes the event channel.
+func (m *Manager) Halt() error {
+	var err error
+	m.halt.Do(func() {
+		close(m.done)
+		m.loop.Wait()
+		err = m.halt0()
+		m.fwd.Wait()
+		close(m.events)
+	})
+	return err
+}
+
+// halt0 halts the encoders. It returns the first error.
+func (m *Manager) halt0() error {
+	var err error
+	for _, d := range m.devs {
+		if e := d.halt0(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// forward tags the events of encoder i and sends them to the manager event channel.
+// Once the manager is halted the events are discarded until the encoder
+// event channel is closed.
+func (m *Manager) forward(i int) {
+	defer m.fwd.Done()
+	for e := range m.devs[i].Events() {
+		select {
+		case m.events <- ManagerEvent{Event: e, Addr: m.addrs[i], Name: m.names[i]}:
+		case <-m.done:
+		}
+	}
+}
+
+// run is the event loop for encoders sharing an INT pin.
+// Any encoder may assert INT, so the status of every encoder is read.
+func (m *Manager) run(pin gpio.PinIn, period time.Duration) {
+	defer m.loop.Done()
+	runInt(pin, period, m.done, func() {
+		for _, d := range m.devs {
+			d.tick()
+		}
+	}, func() error {
+		var err error
+		for _, d := range m.devs {
+			if e := d.poll(); e != nil && err == nil {
+				err = e
+			}
+		}
+		return err
+	})
+}
+
+//-----------------------------------------------------------------------------
@@ -7,6 +7,10 @@ package rei2c
 
 import (
 	"errors"
+	"time"
+
+	"periph.io/x/periph/conn/gpio"
+	"periph.io/x/periph/conn/physic"
 )
 
 //-----------------------------------------------------------------------------
@@ -15,11 +19,49 @@ import (
 type Opts struct {
 	// I2CAddr is the I²C slave address to use.
 	// Solderable links on the board allow the user to specify an arbitrary 7-bit address.
+	// 0 is the default address of the variant. With VariantAuto both default
+	// addresses are tried, DefaultAddress first.
 	I2CAddr uint16
-	// RGB enables an illuminated RGB rotary encoder.
-	RGB bool
-	// DoublePush is the time (in 10ms increments) for the encoder double push. 0 is disabled.
-	DoublePush uint8
+	// Variant is the encoder board variant. 0 (VariantAuto) detects the variant.
+	Variant Variant
+	// GeneralConfig is the encoder general configuration (RGB, Float, Wrap, Direction, ...).
+	GeneralConfig
+	// AntiBounce is the push button anti-bounce period (192us resolution, 0..48.96ms).
+	// 0 is the device default (4.8ms).
+	AntiBounce time.Duration
+	// DoublePush is the push button double push period (10ms resolution, 0..2.55s). 0 is disabled.
+	DoublePush time.Duration
+	// PollPeriod is the period at which the event loop polls the encoder status. 0 is 50ms.
+	// With an interrupt pin it is the longest time the event loop waits for an edge.
+	PollPeriod time.Duration
+	// IntPin is an optional input connected to the open-drain INT output of the encoder.
+	// The status registers are only read when the device asserts INT.
+	// If nil the encoder status is polled.
+	IntPin gpio.PinIn
+	// IntMask is the set of events that assert the INT pin. 0 is IntAll.
+	IntMask IntMask
+	// FadeRGB is the step period (1ms resolution, 0..255ms) for RGB LED fades. 0 is no fading.
+	FadeRGB time.Duration
+	// FadeGP is the step period (1ms resolution, 0..255ms) for GP pin PWM fades. 0 is no fading.
+	FadeGP time.Duration
+	// Accel is an optional counter acceleration profile for fast rotation.
+	// It needs an integer counter.
+	Accel *Accel
+	// Gestures enables push button gesture events (long press, triple click, ...).
+	// While the button is held rotation is reported as EventPressInc and EventPressDec.
+	Gestures *Gestures
+	// Gradient enables counter color mode. The RGB LED is set to the gradient
+	// color for the counter position within the counter minimum and maximum.
+	Gradient Gradient
+	// RestoreSettings applies the settings saved in the EEPROM when the device is opened.
+	// The counter mode of the saved settings overrides Float, a floating point
+	// counter is an error with Accel.
+	// It is not an error if the EEPROM holds no settings.
+	RestoreSettings bool
+	// SettingsAddr is the EEPROM address of the saved settings.
+	SettingsAddr uint8
+	// Vref is the ADC reference voltage (the board supply voltage). 0 is 3.3V.
+	Vref physic.ElectricPotential
 }
 
 // DefaultOpts contains the default options to use.
@@ -29,15 +71,43 @@ const DefaultAddress = 0x55
 
 //-----------------------------------------------------------------------------
 
-func (o *Opts) i2cAddr() (uint16, error) {
+// i2cAddrs returns the I2C addresses to try, in order.
+func (o *Opts) i2cAddrs() ([]uint16, error) {
 	if o.I2CAddr == 0 {
-		return DefaultAddress, nil // default
+		switch o.Variant {
+		case VariantAuto:
+			return []uint16{DefaultAddress, MiniDefaultAddress}, nil // default
+		case VariantMini:
+			return []uint16{MiniDefaultAddress}, nil // default
+		}
+		return []uint16{DefaultAddress}, nil // default
 	}
 	// reserved address
 	if (o.I2CAddr <= 7) || (o.I2CAddr&0x78 == 0x78) {
-		return 0, errors.New("i2c address not supported by device")
+		return nil, errors.New("i2c address not supported by device")
+	}
+	return []uint16{o.I2CAddr}, nil
+}
+
+func (o *Opts) intMask() IntMask {
+	if o.IntMask == 0 {
+		return IntAll // default
+	}
+	return o.IntMask
+}
+
+func (o *Opts) vref() physic.ElectricPotential {
+	if o.Vref <= 0 {
+		return 3300 * physic.MilliVolt // default
+	}
+	return o.Vref
+}
+
+func (o *Opts) pollPeriod() time.Duration {
+	if o.PollPeriod <= 0 {
+		return 50 * time.Millisecond // default
 	}
-	return o.I2CAddr, nil
+	return o.PollPeriod
 }
 
 //-----------------------------------------------------------------------------
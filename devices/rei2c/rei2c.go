@@ -4,12 +4,14 @@
 //-----------------------------------------------------------------------------
 
 // Package rei2c is a driver for the I2C Rotary Encoder V2 Driver
+// and the I2C Encoder Mini.
 //
 // Author
 // Jason Harris (https://github.com/deadsy)
 //
 // Datasheet
 // https://github.com/Fattoresaimon/I2CEncoderV2
+// https://github.com/Fattoresaimon/I2CEncoderMini
 //
 // Product Page
 // https://www.kickstarter.com/projects/1351830006/i2c-encoder-v2
@@ -19,9 +21,12 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
+	"sync"
 	"time"
 
 	"periph.io/x/periph/conn"
+	"periph.io/x/periph/conn/gpio"
 	"periph.io/x/periph/conn/i2c"
 	"periph.io/x/periph/conn/mmr"
 )
@@ -53,6 +58,8 @@ const (
 	RegDPPERIOD = 0x1F // Double push period (1 Byte)
 	RegFADERGB  = 0x20 // Fade timer RGB Encoder (1 Byte)
 	RegFADEGP   = 0x21 // Fade timer GP ports (1 Byte)
+	RegIDCODE   = 0x70 // ID code (1 byte, V2.1)
+	RegVERSION  = 0x71 // Firmware version (1 byte, V2.1)
 	RegEEPROM   = 0x80 // EEPROM memory (128 bytes)
 )
 
@@ -80,6 +87,46 @@ const (
 	statusINT2  = uint8(1 << 7) // Secondary interrupt status
 )
 
+// fstatus bits
+const (
+	fstatusR   = uint8(1 << 0) // Fade process on the red LED in progress
+	fstatusG   = uint8(1 << 1) // Fade process on the green LED in progress
+	fstatusB   = uint8(1 << 2) // Fade process on the blue LED in progress
+	fstatusGP1 = uint8(1 << 3) // Fade process on GP1 in progress
+	fstatusGP2 = uint8(1 << 4) // Fade process on GP2 in progress
+	fstatusGP3 = uint8(1 << 5) // Fade process on GP3 in progress
+	fstatusRGB = fstatusR | fstatusG | fstatusB
+)
+
+// IntMask is a set of encoder events that assert the INT pin.
+type IntMask uint8
+
+// INTCONF bits
+const (
+	IntPushRelease = IntMask(statusPUSHR) // push button has been released
+	IntPushPress   = IntMask(statusPUSHP) // push button has been pressed
+	IntPushDouble  = IntMask(statusPUSHD) // push button has been double pushed
+	IntInc         = IntMask(statusRINC)  // rotated in the increase direction
+	IntDec         = IntMask(statusRDEC)  // rotated in the decrease direction
+	IntMax         = IntMask(statusRMAX)  // maximum counter value has been reached
+	IntMin         = IntMask(statusRMIN)  // minimum counter value has been reached
+	IntINT2        = IntMask(statusINT2)  // secondary interrupt (GP pins and fades)
+	IntPush        = IntPushRelease | IntPushPress | IntPushDouble
+	IntRotate      = IntInc | IntDec | IntMax | IntMin
+	IntAll         = IntPush | IntRotate | IntINT2
+)
+
+// secondary interrupt status bits
+const (
+	i2statusGP1POS = uint8(1 << 0) // GP1 positive edge
+	i2statusGP1NEG = uint8(1 << 1) // GP1 negative edge
+	i2statusGP2POS = uint8(1 << 2) // GP2 positive edge
+	i2statusGP2NEG = uint8(1 << 3) // GP2 negative edge
+	i2statusGP3POS = uint8(1 << 4) // GP3 positive edge
+	i2statusGP3NEG = uint8(1 << 5) // GP3 negative edge
+	i2statusFADE   = uint8(1 << 6) // Fade process finished
+)
+
 //-----------------------------------------------------------------------------
 
 // New returns the Dev object for an rei2c on an I2C bus.
@@ -87,70 +134,175 @@ func New(b i2c.Bus, opts *Opts) (*Dev, error) {
 	if opts == nil {
 		opts = &DefaultOpts
 	}
-	addr, err := opts.i2cAddr()
+	addrs, err := opts.i2cAddrs()
 	if err != nil {
 		return nil, err
 	}
-	d, err := makeDev(&i2c.Dev{Bus: b, Addr: addr}, opts)
+	var d *Dev
+	for _, addr := range addrs {
+		d, err = makeDev(&i2c.Dev{Bus: b, Addr: addr}, opts)
+		if _, ok := err.(*ProbeError); !ok {
+			break // found an encoder, or it can't be configured
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
+	// setup the interrupt pin
+	if opts.IntPin != nil {
+		if err := opts.IntPin.In(gpio.PullUp, gpio.FallingEdge); err != nil {
+			return nil, err
+		}
+	}
+	d.start(true)
 	return d, nil
 }
 
 func makeDev(c conn.Conn, opts *Opts) (*Dev, error) {
 	d := &Dev{
-		opts: *opts,
-		c:    mmr.Dev8{Conn: c, Order: binary.BigEndian},
+		opts:   *opts,
+		c:      mmr.Dev8{Conn: c, Order: binary.BigEndian},
+		events: make(chan Event, eventBufferSize),
+		done:   make(chan struct{}),
+		anim:   animator{wake: make(chan struct{}, 1)},
 	}
-	// reset the device
-	if err := d.reset(); err != nil {
+	// work out the board variant
+	if err := d.identify(opts.Variant); err != nil {
 		return nil, err
 	}
 
-	// check the expected default values for some registers
-	val0, err := d.c.ReadUint8(RegGP1CONF)
-	if err != nil {
+	// reset the device and check it is an encoder
+	if err := d.probe(); err != nil {
 		return nil, err
 	}
-	val1, err := d.c.ReadUint8(RegANTBOUNC)
-	if err != nil {
-		return nil, err
+
+	// the GP pins are in their reset state
+	for i := range d.gp {
+		d.gp[i] = GPPin{d: d, n: i + 1, edge: make(chan struct{}, 1)}
 	}
-	if val0 != 0 || val1 != 25 {
-		return nil, errors.New("bad register values")
+
+	// counter acceleration is for the integer counter
+	if opts.Accel != nil && opts.Float {
+		return nil, errors.New("rei2c: acceleration needs an integer counter")
 	}
 
 	// setup the general configuration
-	var gconf uint8
-	if opts.RGB {
-		// enable an illuminated RGB encoder.
-		gconf |= gconfETYPE
+	err := d.SetGeneralConfig(opts.GeneralConfig)
+	if err != nil {
+		return nil, err
 	}
-	err = d.wrGCONF(gconf)
+
+	// setup the interrupt sources
+	err = d.SetIntMask(opts.intMask())
 	if err != nil {
 		return nil, err
 	}
 
-	// setup the double push time
+	// setup the push button timing
+	if opts.AntiBounce != 0 {
+		err := d.SetAntiBounce(opts.AntiBounce)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if opts.DoublePush != 0 {
-		err := d.c.WriteUint8(RegDPPERIOD, opts.DoublePush)
+		err := d.SetDoublePush(opts.DoublePush)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	// setup the fade timers
+	if opts.FadeRGB != 0 {
+		err := d.SetFadeRGB(opts.FadeRGB)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opts.FadeGP != 0 {
+		err := d.SetFadeGP(opts.FadeGP)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// apply the saved settings
+	if opts.RestoreSettings {
+		err := d.RestoreSettings()
+		if err != nil && err != ErrNoSettings {
+			return nil, err
+		}
+	}
+
 	return d, nil
 }
 
+// probe resets the device and checks the expected reset values of some
+// registers for the board variant.
+func (d *Dev) probe() error {
+	if err := d.reset(); err != nil {
+		return &ProbeError{Variant: d.v.id, Reg: d.v.gconf, Err: err}
+	}
+	for _, x := range d.v.resetValues {
+		val, err := d.c.ReadUint8(x.reg)
+		if err != nil {
+			return &ProbeError{Variant: d.v.id, Reg: x.reg, Err: err}
+		}
+		if val != x.val {
+			return &ProbeError{Variant: d.v.id, Reg: x.reg, Got: val, Want: x.val}
+		}
+	}
+	return nil
+}
+
+// start starts the event loop and the LED animator.
+// The event loop is not started for an encoder serviced by a Manager.
+// The LED animator is not started for a variant without an LED.
+func (d *Dev) start(loop bool) {
+	if loop {
+		d.wg.Add(1)
+		go d.run()
+	}
+	if d.v.led {
+		d.wg.Add(1)
+		go d.runAnimator()
+	}
+}
+
 //-----------------------------------------------------------------------------
 
 // Dev is the device object.
+// It is safe for concurrent use.
 type Dev struct {
 	c    mmr.Dev8
 	opts Opts
+	v    *variant // board variant
+	info Info     // board identification
+
+	// mu serialises device access. It is held for register read-modify-write
+	// sequences (GCONF, GPxCONF, EEPROM bank switching) and protects the
+	// register shadows below. Unexported methods expect it to be held.
+	mu sync.Mutex
+
+	gconf   uint8
+	gp      [NumGP]GPPin // general purpose pins
+	fading  uint8        // FSTATUS bits of the fades in progress
+	fadeRGB uint8        // RGB LED fade timer
+	fadeGP  uint8        // GP pin fade timer
+
+	count      int32     // last counter value (acceleration)
+	countTime  time.Time // time of the last counter value
+	countValid bool      // count and countTime are valid
+
+	gest gestureState // push button gesture state
 
-	gconf uint8
+	anim animator // LED animations
+
+	events  chan Event     // encoder events
+	done    chan struct{}  // closed to stop the event loop and animator
+	halt    sync.Once      // halt only once
+	wg      sync.WaitGroup // event loop and animator goroutines
+	managed bool           // serviced and halted by a Manager
 }
 
 func (d *Dev) String() string {
@@ -158,193 +310,364 @@ func (d *Dev) String() string {
 }
 
 // Halt the device.
+// The event loop and LED animations are stopped, the event channel is closed
+// and a counter step scaled by a held button is restored.
+// An encoder opened by a Manager is halted with Manager.Halt.
 func (d *Dev) Halt() error {
-	return nil
+	if d.managed {
+		return errors.New("rei2c: encoder is halted by its manager")
+	}
+	return d.halt0()
+}
+
+// halt0 stops the goroutines, closes the event channel and restores a
+// counter step scaled by a held button.
+func (d *Dev) halt0() error {
+	var err error
+	d.halt.Do(func() {
+		close(d.done)
+		d.wg.Wait()
+		close(d.events)
+		d.mu.Lock()
+		err = d.restoreStep()
+		d.mu.Unlock()
+	})
+	return err
+}
+
+// SetIntMask sets the encoder events that assert the INT pin.
+func (d *Dev) SetIntMask(m IntMask) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// events the variant doesn't have are ignored
+	val, _ := toVariant(uint8(m), &d.v.statusBits)
+	return d.c.WriteUint8(d.v.intconf, val)
 }
 
-// Poll the device.
-func (d *Dev) Poll() {
-	status, _ := d.rdESTATUS()
-	if status&(statusRINC|statusRDEC) != 0 {
-		n, _ := d.RdCntVal()
-		fmt.Printf("count %d\n", n)
+//-----------------------------------------------------------------------------
+// push button timing
+
+// register units for the push button timing
+const (
+	antiBounceUnit = 192 * time.Microsecond
+	doublePushUnit = 10 * time.Millisecond
+)
+
+// durationReg converts a duration to a register value in multiples of unit.
+// The duration is rounded to the nearest unit and must be within 0..255 units.
+// A non-zero duration that rounds to 0 is out of range.
+func durationReg(t, unit time.Duration) (uint8, error) {
+	n := (t + unit/2) / unit
+	if t < 0 || n > 255 || (t > 0 && n == 0) {
+		return 0, fmt.Errorf("rei2c: duration %s out of range (0, %s..%s)", t, unit/2, 255*unit)
 	}
-	if status&statusPUSHR != 0 {
-		fmt.Printf("pushr\n")
+	return uint8(n), nil
+}
+
+// AntiBounce returns the push button anti-bounce period.
+func (d *Dev) AntiBounce() (time.Duration, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.v.antiBounce {
+		return 0, ErrNotSupported
 	}
-	if status&statusPUSHP != 0 {
-		fmt.Printf("pushp\n")
+	val, err := d.c.ReadUint8(RegANTBOUNC)
+	return time.Duration(val) * antiBounceUnit, err
+}
+
+// SetAntiBounce sets the push button anti-bounce period (192us resolution, 0..48.96ms).
+func (d *Dev) SetAntiBounce(t time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.v.antiBounce {
+		return ErrNotSupported
 	}
-	if status&statusPUSHD != 0 {
-		fmt.Printf("pushd\n")
+	val, err := durationReg(t, antiBounceUnit)
+	if err != nil {
+		return err
 	}
+	return d.c.WriteUint8(RegANTBOUNC, val)
+}
+
+// DoublePush returns the push button double push period. 0 is disabled.
+func (d *Dev) DoublePush() (time.Duration, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	val, err := d.c.ReadUint8(d.v.dpperiod)
+	return time.Duration(val) * doublePushUnit, err
+}
+
+// SetDoublePush sets the push button double push period (10ms resolution, 0..2.55s).
+// 0 disables double push detection.
+func (d *Dev) SetDoublePush(t time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	val, err := durationReg(t, doublePushUnit)
+	if err != nil {
+		return err
+	}
+	return d.c.WriteUint8(d.v.dpperiod, val)
 }
 
 //-----------------------------------------------------------------------------
+// LED control
 
-/*
+// RGB is the color of the encoder LED.
+type RGB struct {
+	R, G, B uint8
+}
 
-// RdMem reads from the rei2c EEPROM.
-func (d *Dev) RdMem(addr uint8) (uint8, error) {
-	var err error
-	if addr <= 0x7f {
-		// switch to bank 0
-		if d.gconf&gconfMBANK != 0 {
-			err = d.wrGCONF(d.gconf & ^gconfMBANK)
-		}
-		addr += RegEEPROM
-	} else {
-		// switch to bank 1
-		if d.gconf&gconfMBANK == 0 {
-			err = d.wrGCONF(d.gconf | gconfMBANK)
-		}
-	}
-	if err != nil {
-		return 0, err
+// RdLED reads the LED color.
+// The 3 LED registers are read with a single transfer.
+func (d *Dev) RdLED() (RGB, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.v.led {
+		return RGB{}, ErrNotSupported
 	}
-	val, err := d.c.ReadUint8(addr)
+	var rgb RGB
+	err := d.c.ReadStruct(RegRLED, &rgb)
 	if err != nil {
-		return 0, err
+		return RGB{}, err
 	}
-	time.Sleep(1 * time.Millisecond)
-	return val, nil
+	return rgb, nil
 }
 
-*/
-
-func (d *Dev) setBank(gconf, adr uint8) (uint8, uint8, error) {
+// WrLED writes the LED color.
+// The 3 LED registers are written with a single transfer.
+// While an LED animation is running the color is shown when it finishes.
+func (d *Dev) WrLED(rgb RGB) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.setLED(rgb)
+}
 
-	x := gconf
+// setLED sets the base LED color and writes it if no animation is running.
+func (d *Dev) setLED(rgb RGB) error {
+	if !d.v.led {
+		return ErrNotSupported
+	}
+	if d.anim.setBase(rgb) {
+		return nil
+	}
+	return d.wrLED(rgb)
+}
 
-	if adr <= 0x7f {
-		// switch to bank 0
-		if x&gconfMBANK != 0 {
-			x &= ^gconfMBANK
-		}
-		adr += RegEEPROM
-	} else {
-		// switch to bank 1
-		if x&gconfMBANK == 0 {
-			x |= gconfMBANK
+// wrLED writes the LED registers.
+func (d *Dev) wrLED(rgb RGB) error {
+	if !d.v.led {
+		return ErrNotSupported
+	}
+	// the device only fades when the color changes
+	fade := false
+	if d.fadeRGB != 0 {
+		var cur RGB
+		err := d.c.ReadStruct(RegRLED, &cur)
+		if err != nil {
+			return err
 		}
+		fade = cur != rgb
 	}
-
-	var err error
-	if x != gconf {
-		err = d.c.WriteUint8(RegGCONF, x)
+	err := d.c.WriteStruct(RegRLED, &rgb)
+	if err != nil {
+		return err
+	}
+	if fade {
+		d.startFade(FadeRGB)
 	}
+	return nil
+}
 
-	return x, adr, err
+//-----------------------------------------------------------------------------
+// counter control
+// The counter registers are 32-bit signed values. Each register is accessed
+// with a single 4 byte transfer so the device never returns a torn value.
+
+// Counter returns the counter value.
+func (d *Dev) Counter() (int32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rdCounterReg(d.v.cval)
 }
 
-// RdMem reads from the rei2c EEPROM.
-func (d *Dev) RdMem(base uint8, n int) ([]uint8, error) {
-	if n == 0 {
-		return nil, nil
-	}
+// CounterMin returns the counter minimum value.
+func (d *Dev) CounterMin() (int32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rdCounterReg(d.v.cmin)
+}
+
+// CounterMax returns the counter maximum value.
+func (d *Dev) CounterMax() (int32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rdCounterReg(d.v.cmax)
+}
+
+// CounterStep returns the counter increment step.
+func (d *Dev) CounterStep() (int32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rdCounterReg(d.v.istep)
+}
 
-	gconf, err := d.c.ReadUint8(RegGCONF)
+// SetCounter sets the counter value.
+func (d *Dev) SetCounter(n int32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	err := d.wrCounterReg(d.v.cval, n)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	d.countValid = false
+	return d.showCounter(float64(n))
+}
 
-	mem := make([]uint8, n)
-	for i := range mem {
-		adr := base + uint8(i)
-		x, adr, err := d.setBank(gconf, adr)
-		if err != nil {
-			return nil, err
-		}
+// SetCounterMin sets the counter minimum value.
+func (d *Dev) SetCounterMin(n int32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.wrCounterReg(d.v.cmin, n)
+}
 
-		gconf = x
+// SetCounterMax sets the counter maximum value.
+func (d *Dev) SetCounterMax(n int32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.wrCounterReg(d.v.cmax, n)
+}
 
-		val, err := d.c.ReadUint8(adr)
-		if err != nil {
-			return nil, err
-		}
-		mem[i] = val
-		time.Sleep(1 * time.Millisecond)
-	}
-	return mem, nil
+// SetCounterStep sets the counter increment step.
+func (d *Dev) SetCounterStep(n int32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stepWritten()
+	return d.wrCounterReg(d.v.istep, n)
 }
 
-//-----------------------------------------------------------------------------
-// LED control
+// CounterFloat returns the floating point counter value.
+func (d *Dev) CounterFloat() (float32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rdFloatReg(d.v.cval)
+}
 
-type RGB struct {
-	R, G, B uint8
+// CounterMinFloat returns the floating point counter minimum value.
+func (d *Dev) CounterMinFloat() (float32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rdFloatReg(d.v.cmin)
 }
 
-func (d *Dev) RdLED() (RGB, error) {
-	r, _ := d.c.ReadUint8(RegRLED)
-	g, _ := d.c.ReadUint8(RegGLED)
-	b, _ := d.c.ReadUint8(RegBLED)
-	return RGB{r, g, b}, nil
-	/*
-		var rgb RGB
-		err := d.c.ReadStruct(RegRLED, &rgb)
-		if err != nil {
-			return RGB{}, err
-		}
-		return rgb, nil
-	*/
+// CounterMaxFloat returns the floating point counter maximum value.
+func (d *Dev) CounterMaxFloat() (float32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rdFloatReg(d.v.cmax)
 }
 
-func (d *Dev) WrLED(rgb RGB) error {
-	d.c.WriteUint8(RegRLED, rgb.R)
-	d.c.WriteUint8(RegGLED, rgb.G)
-	d.c.WriteUint8(RegBLED, rgb.B)
-	return nil
-	//return d.c.WriteStruct(RegRLED, &rgb)
+// CounterStepFloat returns the floating point counter increment step.
+func (d *Dev) CounterStepFloat() (float32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rdFloatReg(d.v.istep)
 }
 
-//-----------------------------------------------------------------------------
-// counter control
+// SetCounterFloat sets the floating point counter value.
+func (d *Dev) SetCounterFloat(x float32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	err := d.wrFloatReg(d.v.cval, x)
+	if err != nil {
+		return err
+	}
+	return d.showCounter(float64(x))
+}
 
-func (d *Dev) RdCntMin() (uint32, error) {
-	return d.c.ReadUint32(RegCMIN)
+// SetCounterMinFloat sets the floating point counter minimum value.
+func (d *Dev) SetCounterMinFloat(x float32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.wrFloatReg(d.v.cmin, x)
 }
 
-func (d *Dev) RdCntMax() (uint32, error) {
-	return d.c.ReadUint32(RegCMAX)
+// SetCounterMaxFloat sets the floating point counter maximum value.
+func (d *Dev) SetCounterMaxFloat(x float32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.wrFloatReg(d.v.cmax, x)
 }
 
-func (d *Dev) RdCntVal() (uint32, error) {
-	n, err := d.c.ReadUint8(RegCVAL + 3)
-	return uint32(n), err
-	//return d.c.ReadUint32(RegCVAL)
+// SetCounterStepFloat sets the floating point counter increment step.
+func (d *Dev) SetCounterStepFloat(x float32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stepWritten()
+	return d.wrFloatReg(d.v.istep, x)
 }
 
-func (d *Dev) RdCntStep() (uint32, error) {
-	return d.c.ReadUint32(RegISTEP)
+// ErrFloatMode is returned by the integer counter functions when the counter is in floating point mode.
+var ErrFloatMode = errors.New("rei2c: counter is in floating point mode")
+
+// ErrIntMode is returned by the floating point counter functions when the counter is in integer mode.
+var ErrIntMode = errors.New("rei2c: counter is in integer mode")
+
+// isFloat returns true if the counter registers are in floating point mode.
+func (d *Dev) isFloat() bool {
+	return d.gconf&gconfDTYPE != 0
 }
 
-func (d *Dev) WrCntMin(n uint32) error {
-	return d.c.WriteUint32(RegCMIN, n)
+// rdCounterReg reads a 32-bit integer counter register.
+func (d *Dev) rdCounterReg(reg uint8) (int32, error) {
+	if d.isFloat() {
+		return 0, ErrFloatMode
+	}
+	n, err := d.c.ReadUint32(reg)
+	return int32(n), err
 }
 
-func (d *Dev) WrCntMax(n uint32) error {
-	return d.c.WriteUint32(RegCMAX, n)
+// wrCounterReg writes a 32-bit integer counter register.
+func (d *Dev) wrCounterReg(reg uint8, n int32) error {
+	if d.isFloat() {
+		return ErrFloatMode
+	}
+	return d.c.WriteUint32(reg, uint32(n))
 }
 
-func (d *Dev) WrCntVal(n uint32) error {
-	return d.c.WriteUint32(RegCVAL, n)
+// rdFloatReg reads a 32-bit floating point counter register.
+func (d *Dev) rdFloatReg(reg uint8) (float32, error) {
+	if !d.isFloat() {
+		return 0, ErrIntMode
+	}
+	n, err := d.c.ReadUint32(reg)
+	return math.Float32frombits(n), err
 }
 
-func (d *Dev) WrCntStep(n uint32) error {
-	return d.c.WriteUint32(RegISTEP, n)
+// wrFloatReg writes a 32-bit floating point counter register.
+func (d *Dev) wrFloatReg(reg uint8, x float32) error {
+	if !d.isFloat() {
+		return ErrIntMode
+	}
+	return d.c.WriteUint32(reg, math.Float32bits(x))
 }
 
 //-----------------------------------------------------------------------------
 
-// rdESTATUS read the encoder status register
+// rdESTATUS read the encoder status register.
+// The status is returned in the V2 bit layout.
 func (d *Dev) rdESTATUS() (uint8, error) {
-	return d.c.ReadUint8(RegESTATUS)
+	val, err := d.c.ReadUint8(d.v.estatus)
+	return fromVariant(val, &d.v.statusBits), err
 }
 
 // wrGCONF write the general configuration register.
+// The value is in the V2 bit layout.
 func (d *Dev) wrGCONF(val uint8) error {
-	err := d.c.WriteUint8(RegGCONF, val)
+	x, ok := toVariant(val, &d.v.gconfBits)
+	if !ok {
+		return ErrNotSupported
+	}
+	err := d.c.WriteUint8(d.v.gconf, x)
 	if err != nil {
 		return err
 	}
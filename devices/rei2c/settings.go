@@ -0,0 +1,273 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"time"
+)
+
+//-----------------------------------------------------------------------------
+// Settings are stored in the encoder EEPROM as a record:
+//
+//	magic   2 bytes "RE"
+//	version 1 byte
+//	length  1 byte (payload length)
+//	payload length bytes
+//	crc     4 bytes (CRC-32 of the preceding bytes)
+//
+// All multi-byte values are big-endian.
+
+// Settings is the encoder configuration that can be saved to the EEPROM.
+type Settings struct {
+	Float      bool          // floating point counter mode
+	Min        int32         // counter minimum (integer mode)
+	Max        int32         // counter maximum (integer mode)
+	Step       int32         // counter step (integer mode)
+	MinFloat   float32       // counter minimum (floating point mode)
+	MaxFloat   float32       // counter maximum (floating point mode)
+	StepFloat  float32       // counter step (floating point mode)
+	LED        RGB           // LED color (V2 variant)
+	DoublePush time.Duration // double push period
+}
+
+// ErrNoSettings is returned when the EEPROM does not hold a settings record.
+var ErrNoSettings = errors.New("rei2c: no settings in eeprom")
+
+// settings record format
+const (
+	settingsMagic0  = 'R'
+	settingsMagic1  = 'E'
+	settingsVersion = 1
+	settingsHdrSize = 4
+	settingsCRCSize = 4
+	settingsV1Size  = 17 // version 1 payload size
+	settingsSize    = settingsHdrSize + settingsV1Size + settingsCRCSize
+)
+
+// settings flags
+const (
+	settingsFLOAT = uint8(1 << 0) // floating point counter mode
+)
+
+//-----------------------------------------------------------------------------
+
+// counterRegs returns the min, max and step register values.
+func (s *Settings) counterRegs() (uint32, uint32, uint32) {
+	if s.Float {
+		return math.Float32bits(s.MinFloat), math.Float32bits(s.MaxFloat), math.Float32bits(s.StepFloat)
+	}
+	return uint32(s.Min), uint32(s.Max), uint32(s.Step)
+}
+
+// setCounterRegs sets the min, max and step from register values.
+func (s *Settings) setCounterRegs(min, max, step uint32) {
+	if s.Float {
+		s.MinFloat = math.Float32frombits(min)
+		s.MaxFloat = math.Float32frombits(max)
+		s.StepFloat = math.Float32frombits(step)
+	} else {
+		s.Min = int32(min)
+		s.Max = int32(max)
+		s.Step = int32(step)
+	}
+}
+
+// marshal encodes the settings as an EEPROM record.
+func (s *Settings) marshal() ([]byte, error) {
+	dp, err := durationReg(s.DoublePush, doublePushUnit)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, settingsSize)
+	buf[0] = settingsMagic0
+	buf[1] = settingsMagic1
+	buf[2] = settingsVersion
+	buf[3] = settingsV1Size
+	p := buf[settingsHdrSize:]
+	if s.Float {
+		p[0] |= settingsFLOAT
+	}
+	min, max, step := s.counterRegs()
+	binary.BigEndian.PutUint32(p[1:], min)
+	binary.BigEndian.PutUint32(p[5:], max)
+	binary.BigEndian.PutUint32(p[9:], step)
+	p[13] = s.LED.R
+	p[14] = s.LED.G
+	p[15] = s.LED.B
+	p[16] = dp
+	n := settingsHdrSize + settingsV1Size
+	binary.BigEndian.PutUint32(buf[n:], crc32.ChecksumIEEE(buf[:n]))
+	return buf, nil
+}
+
+// unmarshal decodes an EEPROM record.
+func (s *Settings) unmarshal(buf []byte) error {
+	if len(buf) < settingsHdrSize || buf[0] != settingsMagic0 || buf[1] != settingsMagic1 {
+		return ErrNoSettings
+	}
+	if buf[2] != settingsVersion || buf[3] != settingsV1Size {
+		return fmt.Errorf("rei2c: unsupported settings version %d", buf[2])
+	}
+	n := settingsHdrSize + int(buf[3])
+	if len(buf) < n+settingsCRCSize {
+		return errors.New("rei2c: short settings record")
+	}
+	if binary.BigEndian.Uint32(buf[n:]) != crc32.ChecksumIEEE(buf[:n]) {
+		return errors.New("rei2c: bad settings crc")
+	}
+	p := buf[settingsHdrSize:n]
+	s.Float = p[0]&settingsFLOAT != 0
+	s.setCounterRegs(binary.BigEndian.Uint32(p[1:]), binary.BigEndian.Uint32(p[5:]), binary.BigEndian.Uint32(p[9:]))
+	s.LED = RGB{R: p[13], G: p[14], B: p[15]}
+	s.DoublePush = time.Duration(p[16]) * doublePushUnit
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+
+// Settings returns the current device settings.
+func (d *Dev) Settings() (*Settings, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.settings()
+}
+
+// settings reads the current device settings.
+func (d *Dev) settings() (*Settings, error) {
+	s := &Settings{Float: d.isFloat()}
+	min, err := d.c.ReadUint32(d.v.cmin)
+	if err != nil {
+		return nil, err
+	}
+	max, err := d.c.ReadUint32(d.v.cmax)
+	if err != nil {
+		return nil, err
+	}
+	step, err := d.c.ReadUint32(d.v.istep)
+	if err != nil {
+		return nil, err
+	}
+	s.setCounterRegs(min, max, step)
+	if d.v.led {
+		err = d.c.ReadStruct(RegRLED, &s.LED)
+		if err != nil {
+			return nil, err
+		}
+	}
+	dp, err := d.c.ReadUint8(d.v.dpperiod)
+	if err != nil {
+		return nil, err
+	}
+	s.DoublePush = time.Duration(dp) * doublePushUnit
+	return s, nil
+}
+
+// ApplySettings writes the settings to the device.
+// The device counter mode is set to the counter mode of the settings.
+// Settings with a floating point counter are an error with acceleration enabled.
+func (d *Dev) ApplySettings(s *Settings) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.applySettings(s)
+}
+
+// applySettings writes the settings to the device.
+func (d *Dev) applySettings(s *Settings) error {
+	dp, err := durationReg(s.DoublePush, doublePushUnit)
+	if err != nil {
+		return err
+	}
+	// counter acceleration is for the integer counter
+	if s.Float && d.opts.Accel != nil {
+		return errors.New("rei2c: acceleration needs an integer counter")
+	}
+	if s.Float != d.isFloat() {
+		err := d.wrGCONF(d.gconf ^ gconfDTYPE)
+		if err != nil {
+			return err
+		}
+		d.countValid = false
+	}
+	min, max, step := s.counterRegs()
+	err = d.c.WriteUint32(d.v.cmin, min)
+	if err != nil {
+		return err
+	}
+	err = d.c.WriteUint32(d.v.cmax, max)
+	if err != nil {
+		return err
+	}
+	d.stepWritten()
+	err = d.c.WriteUint32(d.v.istep, step)
+	if err != nil {
+		return err
+	}
+	if d.v.led {
+		err = d.setLED(s.LED)
+		if err != nil {
+			return err
+		}
+	}
+	return d.c.WriteUint8(d.v.dpperiod, dp)
+}
+
+// SaveSettings saves the current device settings to the EEPROM.
+func (d *Dev) SaveSettings() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s, err := d.settings()
+	if err != nil {
+		return err
+	}
+	buf, err := s.marshal()
+	if err != nil {
+		return err
+	}
+	return d.wrMem(d.opts.SettingsAddr, buf)
+}
+
+// LoadSettings reads the settings saved in the EEPROM.
+// It returns ErrNoSettings if the EEPROM does not hold a settings record.
+func (d *Dev) LoadSettings() (*Settings, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.loadSettings()
+}
+
+// loadSettings reads the settings saved in the EEPROM.
+func (d *Dev) loadSettings() (*Settings, error) {
+	if err := d.checkMem(d.opts.SettingsAddr, settingsSize); err != nil {
+		return nil, err
+	}
+	buf, err := d.rdMem(d.opts.SettingsAddr, settingsSize)
+	if err != nil {
+		return nil, err
+	}
+	s := &Settings{}
+	err = s.unmarshal(buf)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RestoreSettings applies the settings saved in the EEPROM to the device.
+func (d *Dev) RestoreSettings() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s, err := d.loadSettings()
+	if err != nil {
+		return err
+	}
+	return d.applySettings(s)
+}
+
+//-----------------------------------------------------------------------------
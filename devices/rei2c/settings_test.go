@@ -0,0 +1,93 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"testing"
+	"time"
+)
+
+//-----------------------------------------------------------------------------
+
+func TestSettingsRecord(t *testing.T) {
+	intSettings := Settings{
+		Min:        -100,
+		Max:        1000,
+		Step:       5,
+		LED:        RGB{R: 1, G: 2, B: 3},
+		DoublePush: 250 * time.Millisecond,
+	}
+	floatSettings := Settings{
+		Float:      true,
+		MinFloat:   -1.5,
+		MaxFloat:   2.25,
+		StepFloat:  0.125,
+		LED:        RGB{R: 255},
+		DoublePush: 2550 * time.Millisecond,
+	}
+	tests := []struct {
+		name  string
+		s     Settings
+		edit  func(buf []byte) // corrupt the record
+		size  int              // record size to unmarshal, 0 is the full record
+		fail  bool             // unmarshal should fail
+		noRec bool             // unmarshal should return ErrNoSettings
+	}{
+		{name: "int", s: intSettings},
+		{name: "float", s: floatSettings},
+		{name: "bad magic", s: intSettings, edit: func(buf []byte) { buf[0] = 'X' }, fail: true, noRec: true},
+		{name: "erased", s: intSettings, edit: func(buf []byte) {
+			for i := range buf {
+				buf[i] = 0xff
+			}
+		}, fail: true, noRec: true},
+		{name: "bad crc", s: intSettings, edit: func(buf []byte) { buf[settingsHdrSize+5] ^= 0x01 }, fail: true},
+		{name: "unknown version", s: floatSettings, edit: func(buf []byte) { buf[2] = settingsVersion + 1 }, fail: true},
+		{name: "short", s: intSettings, size: settingsSize - 1, fail: true},
+	}
+	for _, tc := range tests {
+		buf, err := tc.s.marshal()
+		if err != nil {
+			t.Fatalf("%s: marshal: %s", tc.name, err)
+		}
+		if len(buf) != settingsSize {
+			t.Fatalf("%s: record size %d, want %d", tc.name, len(buf), settingsSize)
+		}
+		if tc.edit != nil {
+			tc.edit(buf)
+		}
+		if tc.size != 0 {
+			buf = buf[:tc.size]
+		}
+		var s Settings
+		err = s.unmarshal(buf)
+		if tc.fail {
+			if err == nil {
+				t.Errorf("%s: unmarshal succeeded", tc.name)
+			}
+			if (err == ErrNoSettings) != tc.noRec {
+				t.Errorf("%s: unmarshal error %v", tc.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unmarshal: %s", tc.name, err)
+			continue
+		}
+		if s != tc.s {
+			t.Errorf("%s: got %+v, want %+v", tc.name, s, tc.s)
+		}
+	}
+}
+
+func TestSettingsDuration(t *testing.T) {
+	s := Settings{DoublePush: 3 * time.Second}
+	if _, err := s.marshal(); err == nil {
+		t.Error("out of range double push period was accepted")
+	}
+}
+
+//-----------------------------------------------------------------------------
@@ -0,0 +1,277 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+//-----------------------------------------------------------------------------
+
+package rei2c
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//-----------------------------------------------------------------------------
+// board variants
+// The I2C Encoder Mini has the counter, push button and EEPROM functions of
+// the I2C Encoder V2 at different register addresses, with no RGB LED, GP
+// pins, fades, anti-bounce setting or floating point counter.
+// The driver keeps GCONF, ESTATUS and INTCONF values in the V2 bit layout and
+// translates them for the variant when the registers are accessed.
+
+// I2C Encoder Mini register addresses
+const (
+	RegMiniGCONF    = 0x00 // General Configuration (1 byte)
+	RegMiniINTCONF  = 0x01 // INT pin Configuration (1 byte)
+	RegMiniESTATUS  = 0x02 // Encoder Status (1 byte)
+	RegMiniCVAL     = 0x03 // Counter Value (4 bytes)
+	RegMiniCMAX     = 0x07 // Counter Max value (4 bytes)
+	RegMiniCMIN     = 0x0B // Counter Min value (4 bytes)
+	RegMiniISTEP    = 0x0F // Increment step value (4 bytes)
+	RegMiniDPPERIOD = 0x13 // Double push period (1 byte)
+	RegMiniADDRESS  = 0x14 // I2C address (1 byte)
+	RegMiniIDCODE   = 0x70 // ID code (1 byte)
+	RegMiniVERSION  = 0x71 // Firmware version (1 byte)
+	RegMiniEEPROM   = 0x81 // EEPROM memory (127 bytes)
+)
+
+// MiniDefaultAddress is the default I2C address of the I2C Encoder Mini.
+const MiniDefaultAddress = 0x20
+
+// IDCODE register values
+const (
+	v2IDCODE   = 0x53 // I2C Encoder V2.1 (the V2.0 has no IDCODE register)
+	miniIDCODE = 0x39 // I2C Encoder Mini
+)
+
+// Variant is an encoder board variant.
+type Variant uint8
+
+// encoder board variants
+const (
+	VariantAuto Variant = iota // detect the board variant
+	VariantV2                  // I2C Encoder V2
+	VariantMini                // I2C Encoder Mini
+)
+
+var variantNames = [...]string{
+	VariantAuto: "auto",
+	VariantV2:   "I2C Encoder V2",
+	VariantMini: "I2C Encoder Mini",
+}
+
+func (v Variant) String() string {
+	if int(v) < len(variantNames) {
+		return variantNames[v]
+	}
+	return fmt.Sprintf("Variant(%d)", v)
+}
+
+// ErrNotSupported is returned for a function the encoder variant doesn't have.
+var ErrNotSupported = errors.New("rei2c: not supported by the encoder variant")
+
+//-----------------------------------------------------------------------------
+
+// regValue is a register value.
+type regValue struct {
+	reg, val uint8
+}
+
+// variant describes the registers and functions of a board variant.
+type variant struct {
+	id     Variant
+	idcode uint8 // IDCODE register value
+	// register values after reset, used to check the board variant
+	resetValues []regValue
+	// shared register addresses
+	gconf, intconf, estatus, cval, cmax, cmin, istep, dpperiod uint8
+	// variant bit for each V2 bit of GCONF and ESTATUS/INTCONF, 0 is not supported
+	gconfBits, statusBits [8]uint8
+
+	eeprom     uint8 // first EEPROM register
+	eepromSize int   // EEPROM size in bytes
+	banked     bool  // the EEPROM is 2 banks selected by the GCONF MBANK bit
+	numGP      int   // number of GP pins
+	led        bool  // RGB LED and fade timers
+	antiBounce bool  // anti-bounce period register
+	float      bool  // floating point counter mode
+}
+
+// identity bit map
+var sameBits = [8]uint8{1 << 0, 1 << 1, 1 << 2, 1 << 3, 1 << 4, 1 << 5, 1 << 6, 1 << 7}
+
+var variantV2 = variant{
+	id:          VariantV2,
+	idcode:      v2IDCODE,
+	resetValues: []regValue{{RegGP1CONF, 0}, {RegANTBOUNC, 25}},
+	gconf:       RegGCONF,
+	intconf:     RegINTCONF,
+	estatus:     RegESTATUS,
+	cval:        RegCVAL,
+	cmax:        RegCMAX,
+	cmin:        RegCMIN,
+	istep:       RegISTEP,
+	dpperiod:    RegDPPERIOD,
+	gconfBits:   sameBits,
+	statusBits:  sameBits,
+	eeprom:      RegEEPROM,
+	eepromSize:  EEPROMSize,
+	banked:      true,
+	numGP:       NumGP,
+	led:         true,
+	antiBounce:  true,
+	float:       true,
+}
+
+var variantMini = variant{
+	id:          VariantMini,
+	idcode:      miniIDCODE,
+	resetValues: []regValue{{RegMiniIDCODE, miniIDCODE}},
+	gconf:       RegMiniGCONF,
+	intconf:     RegMiniINTCONF,
+	estatus:     RegMiniESTATUS,
+	cval:        RegMiniCVAL,
+	cmax:        RegMiniCMAX,
+	cmin:        RegMiniCMIN,
+	istep:       RegMiniISTEP,
+	dpperiod:    RegMiniDPPERIOD,
+	// DTYPE, WRAPE, DIRE, IPUD, RMOD, ETYPE, MBANK, RESET
+	gconfBits: [8]uint8{0, 0x01, 0x02, 0x04, 0x08, 0, 0, 0x80},
+	// PUSHR, PUSHP, PUSHD, RINC, RDEC, RMAX, RMIN, INT2
+	// The Mini long push status (0x08) is not used, see Gestures.LongPress.
+	statusBits: [8]uint8{0x01, 0x02, 0x04, 0x10, 0x20, 0x40, 0x80, 0},
+	eeprom:     RegMiniEEPROM,
+	eepromSize: 0x100 - RegMiniEEPROM,
+}
+
+// toVariant converts a V2 layout value to the variant bit layout.
+// It returns false if a bit is not supported by the variant. The supported
+// bits are converted either way.
+func toVariant(x uint8, bits *[8]uint8) (uint8, bool) {
+	var y uint8
+	ok := true
+	for i, b := range bits {
+		if x&(1<<uint(i)) == 0 {
+			continue
+		}
+		if b == 0 {
+			ok = false
+		}
+		y |= b
+	}
+	return y, ok
+}
+
+// fromVariant converts a variant layout value to the V2 bit layout.
+// Bits not in the V2 layout are dropped.
+func fromVariant(y uint8, bits *[8]uint8) uint8 {
+	var x uint8
+	for i, b := range bits {
+		if b != 0 && y&b != 0 {
+			x |= 1 << uint(i)
+		}
+	}
+	return x
+}
+
+//-----------------------------------------------------------------------------
+
+// Info is the identification of an encoder.
+type Info struct {
+	Variant Variant // board variant
+	ID      uint8   // IDCODE register value, 0 if the board has no IDCODE register
+	Version uint8   // firmware version, 0 if unknown
+	RGB     bool    // the board has an RGB LED
+	GP      int     // number of GP pins
+	Float   bool    // the counter has a floating point mode
+}
+
+func (i Info) String() string {
+	var caps []string
+	if i.RGB {
+		caps = append(caps, "rgb")
+	}
+	if i.GP != 0 {
+		caps = append(caps, fmt.Sprintf("%d gp", i.GP))
+	}
+	if i.Float {
+		caps = append(caps, "float")
+	}
+	s := i.Variant.String()
+	if i.ID != 0 {
+		s += fmt.Sprintf(" id 0x%02x version 0x%02x", i.ID, i.Version)
+	}
+	if len(caps) != 0 {
+		s += " (" + strings.Join(caps, ", ") + ")"
+	}
+	return s
+}
+
+// ProbeError is returned when an encoder can't be identified.
+type ProbeError struct {
+	Variant Variant // board variant being probed, VariantAuto when detecting
+	Reg     uint8   // register address
+	Got     uint8   // register value
+	Want    uint8   // expected register value
+	Err     error   // bus error, nil if the register value was unexpected
+}
+
+func (e *ProbeError) Error() string {
+	name := "encoder"
+	if e.Variant != VariantAuto {
+		name = e.Variant.String()
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("rei2c: no response probing %s register 0x%02x: %s", name, e.Reg, e.Err)
+	}
+	return fmt.Sprintf("rei2c: not an %s, register 0x%02x is 0x%02x (expected 0x%02x)", name, e.Reg, e.Got, e.Want)
+}
+
+// Unwrap returns the bus error.
+func (e *ProbeError) Unwrap() error {
+	return e.Err
+}
+
+// identify sets the board variant and reads the board identification.
+// VariantAuto uses the IDCODE register to tell the boards apart.
+func (d *Dev) identify(v Variant) error {
+	id, err := d.c.ReadUint8(RegIDCODE)
+	if err != nil {
+		return &ProbeError{Variant: v, Reg: RegIDCODE, Err: err}
+	}
+	if v == VariantAuto {
+		v = VariantV2
+		if id == miniIDCODE {
+			v = VariantMini
+		}
+	}
+	switch v {
+	case VariantV2:
+		d.v = &variantV2
+	case VariantMini:
+		d.v = &variantMini
+	default:
+		return fmt.Errorf("rei2c: unknown variant %s", v)
+	}
+	d.info = Info{Variant: v, RGB: d.v.led, GP: d.v.numGP, Float: d.v.float}
+	if id == d.v.idcode {
+		d.info.ID = id
+		d.info.Version, err = d.c.ReadUint8(RegVERSION)
+		if err != nil {
+			return &ProbeError{Variant: v, Reg: RegVERSION, Err: err}
+		}
+	}
+	return nil
+}
+
+// Info returns the identification of the encoder.
+func (d *Dev) Info() Info {
+	return d.info
+}
+
+// Variant returns the board variant of the encoder.
+func (d *Dev) Variant() Variant {
+	return d.v.id
+}
+
+//-----------------------------------------------------------------------------
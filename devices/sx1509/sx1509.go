@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 	"math/bits"
+	"sync"
+	"time"
 
 	"periph.io/x/periph/conn"
 	"periph.io/x/periph/conn/i2c"
@@ -148,13 +150,22 @@ func New(b i2c.Bus, opts *Opts) (*Dev, error) {
 	if err != nil {
 		return nil, err
 	}
+	// select the first scan row
+	if err := d.c.WriteUint8(RegDataA, ^uint8(1)); err != nil {
+		return nil, err
+	}
+	// start the key scanner
+	d.wg.Add(1)
+	go d.run()
 	return d, nil
 }
 
 func makeDev(c conn.Conn, opts *Opts) (*Dev, error) {
 	d := &Dev{
-		opts: *opts,
-		c:    mmr.Dev8{Conn: c, Order: binary.LittleEndian},
+		opts:   *opts,
+		c:      mmr.Dev8{Conn: c, Order: binary.LittleEndian},
+		events: make(chan KeyEvent, eventBufferSize),
+		done:   make(chan struct{}),
 	}
 	// reset the device
 	if err := d.reset(); err != nil {
@@ -197,7 +208,12 @@ type Dev struct {
 	keys   uint64                        // current debounced key state
 	idx    uint                          // buffer index
 	row    uint                          // current scan row
+	busErr bool                          // a bus error has been reported
 
+	events chan KeyEvent  // key events
+	done   chan struct{}  // closed to stop the key scanner
+	halt   sync.Once      // close done only once
+	wg     sync.WaitGroup // key scanner goroutine
 }
 
 func (d *Dev) String() string {
@@ -205,14 +221,81 @@ func (d *Dev) String() string {
 }
 
 // Halt the device.
+// The key scanner is stopped and the event channel is closed.
 func (d *Dev) Halt() error {
+	d.halt.Do(func() { close(d.done) })
+	d.wg.Wait()
+	return nil
+}
+
+// reset the device.
+func (d *Dev) reset() error {
+	if err := d.c.WriteUint8(RegReset, 0x12); err != nil {
+		return err
+	}
+	if err := d.c.WriteUint8(RegReset, 0x34); err != nil {
+		return err
+	}
 	return nil
 }
 
-// Poll the device.
-func (d *Dev) Poll() {
+//-----------------------------------------------------------------------------
+// key scanning
+
+// KeyEvent is a keypad key transition.
+type KeyEvent struct {
+	Key  int       // key number 0..63 (Row * SX1509_MAX_COLS + Col)
+	Row  int       // scan row
+	Col  int       // scan column
+	Down bool      // true if the key was pressed, false if released
+	Err  error     // bus error, the other fields except Time are not valid
+	Time time.Time // time the transition was detected
+}
+
+func (e KeyEvent) String() string {
+	if e.Err != nil {
+		return fmt.Sprintf("error %s", e.Err)
+	}
+	if e.Down {
+		return fmt.Sprintf("key %d (%d,%d) dn", e.Key, e.Row, e.Col)
+	}
+	return fmt.Sprintf("key %d (%d,%d) up", e.Key, e.Row, e.Col)
+}
+
+// eventBufferSize is the number of events buffered on the event channel.
+const eventBufferSize = 16
+
+// Events returns the channel on which key events are delivered.
+// The channel is closed when the device is halted.
+func (d *Dev) Events() <-chan KeyEvent {
+	return d.events
+}
+
+// run is the key scanner goroutine. It scans a row each scan period until halted.
+func (d *Dev) run() {
+	defer d.wg.Done()
+	defer close(d.events)
+	t := time.NewTicker(d.opts.scanPeriod())
+	defer t.Stop()
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-t.C:
+			d.scan()
+		}
+	}
+}
+
+// scan reads the current row and generates events for any key changes.
+// Bus errors are reported with a KeyEvent with Err set, once until the bus recovers.
+func (d *Dev) scan() {
 	// read the column bits
-	col, _ := d.c.ReadUint8(RegDataB)
+	col, err := d.c.ReadUint8(RegDataB)
+	if err != nil {
+		d.busError(err)
+		return
+	}
 	// add it to the sample buffer
 	d.sample[d.idx] &= ^(uint64(0xff) << (d.row << 3))
 	d.sample[d.idx] |= uint64(col^0xff) << (d.row << 3)
@@ -223,8 +306,9 @@ func (d *Dev) Poll() {
 	}
 	// has it changed?
 	if keys != d.keys {
-		keyEvents(keys & ^d.keys, "dn")
-		keyEvents(^keys&d.keys, "up")
+		now := time.Now()
+		d.keyEvents(keys & ^d.keys, true, now)
+		d.keyEvents(^keys&d.keys, false, now)
 		d.keys = keys
 	}
 	// increment/wrap the row index
@@ -239,18 +323,47 @@ func (d *Dev) Poll() {
 		}
 	}
 	// write the row selection bits
-	d.c.WriteUint8(RegDataA, ^(1 << d.row))
+	err = d.c.WriteUint8(RegDataA, ^(1 << d.row))
+	d.busError(err)
 }
 
-// reset the device.
-func (d *Dev) reset() error {
-	if err := d.c.WriteUint8(RegReset, 0x12); err != nil {
-		return err
+// busError reports a bus error when the bus starts failing.
+// Further errors are not reported until a scan succeeds.
+func (d *Dev) busError(err error) {
+	if err == nil {
+		d.busErr = false
+		return
 	}
-	if err := d.c.WriteUint8(RegReset, 0x34); err != nil {
-		return err
+	if !d.busErr {
+		d.busErr = true
+		d.send(KeyEvent{Err: err, Time: time.Now()})
+	}
+}
+
+// keyEvents sends key events for the key bits.
+func (d *Dev) keyEvents(bits uint64, down bool, now time.Time) {
+	for key := getKey(&bits); key >= 0; key = getKey(&bits) {
+		e := KeyEvent{
+			Key:  key,
+			Row:  key / SX1509_MAX_COLS,
+			Col:  key % SX1509_MAX_COLS,
+			Down: down,
+			Time: now,
+		}
+		if !d.send(e) {
+			return
+		}
+	}
+}
+
+// send an event to the event channel. It returns false if the device was halted.
+func (d *Dev) send(e KeyEvent) bool {
+	select {
+	case d.events <- e:
+		return true
+	case <-d.done:
+		return false
 	}
-	return nil
 }
 
 //-----------------------------------------------------------------------------
@@ -266,11 +379,4 @@ func getKey(val *uint64) int {
 	return key
 }
 
-// generate key events
-func keyEvents(bits uint64, event string) {
-	for key := getKey(&bits); key >= 0; key = getKey(&bits) {
-		fmt.Printf("key %d event %s\n", key, event)
-	}
-}
-
 //-----------------------------------------------------------------------------
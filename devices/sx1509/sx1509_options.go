@@ -7,6 +7,7 @@ package sx1509
 
 import (
 	"errors"
+	"time"
 )
 
 //-----------------------------------------------------------------------------
@@ -25,6 +26,8 @@ type Opts struct {
 	I2CAddr uint16
 	// Init is an optional set of initial register values.
 	Init []RegInit
+	// ScanPeriod is the time between successive keypad row scans. 0 is 4ms.
+	ScanPeriod time.Duration
 }
 
 // DefaultOpts contains the default options to use.
@@ -43,4 +46,11 @@ func (o *Opts) i2cAddr() (uint16, error) {
 	}
 }
 
+func (o *Opts) scanPeriod() time.Duration {
+	if o.ScanPeriod <= 0 {
+		return 4 * time.Millisecond // default
+	}
+	return o.ScanPeriod
+}
+
 //-----------------------------------------------------------------------------